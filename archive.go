@@ -0,0 +1,221 @@
+package main
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// tarReader return reader of tar archive of paths created in background, so
+// it can be streamed into encoder without temporary files
+func tarReader(paths []string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := writeTar(tw, paths)
+		if cerr := tw.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// writeTar add files, directories and symlinks under paths to tw, names in
+// archive are paths as given without leading slash
+func writeTar(tw *tar.Writer, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no FILE to archive")
+	}
+	for _, root := range paths {
+		err := filepath.Walk(root, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			var link string
+			switch {
+			case info.Mode()&os.ModeSymlink != 0:
+				if link, err = os.Readlink(name); err != nil {
+					return err
+				}
+			case !info.IsDir() && !info.Mode().IsRegular():
+				return fmt.Errorf("%s: unsupported file type %v", name, info.Mode()&os.ModeType)
+			}
+			header, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return err
+			}
+			header.Name = archiveName(name)
+			if header.Name == "" {
+				return nil // root of relative paths like . or ..
+			}
+			if info.IsDir() {
+				header.Name += "/"
+			}
+			if err = tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			file, err := os.Open(name) // #nosec G304 -- files given by user
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			_, err = io.Copy(tw, file)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("cannot archive: %v", err)
+		}
+	}
+	return nil
+}
+
+// archiveName return name in archive of file name, which is slash separated
+// relative name without leading slash and parent directories, like GNU tar
+func archiveName(name string) string {
+	name = filepath.ToSlash(filepath.Clean(name))
+	for {
+		trimmed := strings.TrimPrefix(strings.TrimLeft(name, "/"), "../")
+		if trimmed == name {
+			break
+		}
+		name = trimmed
+	}
+	if name == "." || name == ".." {
+		return ""
+	}
+	return name
+}
+
+// extractingWriter extract tar archive written to it in background
+type extractingWriter struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+// extractWriter return writer which extract tar archive written to it under
+// root, so it can be streamed from decoder, Close wait for extraction to end
+func extractWriter(root string) io.WriteCloser {
+	pr, pw := io.Pipe()
+	w := &extractingWriter{pw: pw, done: make(chan error, 1)}
+	go func() {
+		err := extractTar(pr, root)
+		if err == nil {
+			// tar ends with zero blocks, padding after them is allowed
+			_, err = io.Copy(ioutil.Discard, pr)
+		}
+		if err != nil {
+			err = fmt.Errorf("cannot extract: %v", err)
+		}
+		pr.CloseWithError(err)
+		w.done <- err
+	}()
+	return w
+}
+
+func (w *extractingWriter) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+func (w *extractingWriter) Close() error {
+	w.pw.Close()
+	return <-w.done
+}
+
+// extractTar create files, directories and symlinks of tar archive under
+// root, entries and symlinks escaping root are refused
+func extractTar(input io.Reader, root string) error {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return err
+	}
+	tr := tar.NewReader(input)
+
+	// permissions of directories are set at the end, so read-only directories
+	// can be filled first
+	var dirs []*tar.Header
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeXGlobalHeader || (header.Typeflag == tar.TypeDir && path.Clean(header.Name) == ".") {
+			continue // e.g. comment of git archive or root of tar -C DIR .
+		}
+		name, err := safePath(root, strings.TrimSuffix(header.Name, "/"))
+		if err != nil {
+			return err
+		}
+		mode := header.FileInfo().Mode().Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(name, 0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, header)
+		case tar.TypeReg, tar.TypeRegA:
+			if err = extractFile(name, mode, tr); err != nil {
+				return fmt.Errorf("%s: %v", header.Name, err)
+			}
+		case tar.TypeSymlink:
+			// any .. is refused, lexical check of target would miss chained
+			// symlinks like d -> . and a -> d/d/../..
+			if path.IsAbs(header.Linkname) || hasParentElement(header.Linkname) {
+				return fmt.Errorf("unsafe symlink %s to %s may escape %s", header.Name, header.Linkname, root)
+			}
+			if err = os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+				return err
+			}
+			if err = os.Symlink(header.Linkname, name); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s: unsupported entry type %q", header.Name, header.Typeflag)
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		name, _ := safePath(root, strings.TrimSuffix(dirs[i].Name, "/")) // validated above
+		if err := os.Chmod(name, dirs[i].FileInfo().Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasParentElement report whether slash separated name has .. element
+func hasParentElement(name string) bool {
+	for _, element := range strings.Split(name, "/") {
+		if element == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+func extractFile(name string, mode os.FileMode, content io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600) // #nosec G304 -- checked by safePath
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(file, content); err != nil { // #nosec G110 -- size is limited by archive
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(name, mode)
+}
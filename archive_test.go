@@ -0,0 +1,162 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_archiveName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"dir/file", "dir/file"},
+		{"./dir/", "dir"},
+		{"/etc/passwd", "etc/passwd"},
+		{"../../config", "config"},
+		{"dir/../..", ""},
+		{".", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := archiveName(tt.name); got != tt.want {
+				t.Errorf("archiveName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runCodecWithArchive(t *testing.T) {
+	fake := &fakeClipboard{}
+	defer func(original func() (clipboard, error)) { newClipboard = original }(newClipboard)
+	newClipboard = func() (clipboard, error) { return fake, nil }
+
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+	source := filepath.Join(dir, "source")
+	if err = os.MkdirAll(filepath.Join(source, "d"), 0755); err != nil {
+		t.Fatalf("cannot create directory: %v", err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(source, "d", "f"), []byte("hello"), 0640); err != nil {
+		t.Fatalf("cannot write file: %v", err)
+	}
+	if err = os.Symlink("f", filepath.Join(source, "d", "l")); err != nil {
+		t.Fatalf("cannot create symlink: %v", err)
+	}
+
+	// archive is relative to working directory
+	defer func(wd string) { os.Chdir(wd) }(mustGetwd(t))
+	if err = os.Chdir(dir); err != nil {
+		t.Fatalf("cannot change directory: %v", err)
+	}
+	encode := &codecOptions{archive: "tar", compression: "gzip", paths: []string{"source"}, toClipboard: true, wrapAfter: 76}
+	if err = runCodec(encode, "source"); err != nil {
+		t.Fatalf("runCodec() encode error = %v", err)
+	}
+	decode := &codecOptions{decode: true, compression: "auto", extract: "target", fromClipboard: true}
+	if err = runCodec(decode, ""); err != nil {
+		t.Fatalf("runCodec() decode error = %v", err)
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join("target", "source", "d", "f"))
+	if err != nil || string(got) != "hello" {
+		t.Errorf("extracted file = %q, %v, want %q", got, err, "hello")
+	}
+	if info, err := os.Stat(filepath.Join("target", "source", "d", "f")); err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("extracted file mode = %v, %v, want %v", info.Mode().Perm(), err, os.FileMode(0640))
+	}
+	if link, err := os.Readlink(filepath.Join("target", "source", "d", "l")); err != nil || link != "f" {
+		t.Errorf("extracted symlink = %q, %v, want %q", link, err, "f")
+	}
+
+	for _, opts := range []codecOptions{
+		{archive: "zip", paths: []string{"source"}},
+		{archive: "tar", lines: true, paths: []string{"source"}},
+		{archive: "tar", fromClipboard: true},
+		{decode: true, extract: "target", toClipboard: true},
+	} {
+		if err := runCodec(&opts, ""); err == nil {
+			t.Errorf("runCodec(%+v) error = nil", opts)
+		}
+	}
+	for _, tt := range []struct {
+		opts    codecOptions
+		wantErr string
+	}{
+		{codecOptions{decode: true, archive: "tar", paths: []string{"source"}}, "--archive can be used only when encoding"},
+		{codecOptions{extract: "target"}, "--extract can be used only when decoding"},
+	} {
+		if err := runCodec(&tt.opts, ""); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("runCodec(%+v) error = %v, want %q", tt.opts, err, tt.wantErr)
+		}
+	}
+}
+
+func Test_extractTar(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	tests := []struct {
+		name    string
+		headers []tar.Header
+		wantErr string
+	}{
+		{"parent directory", []tar.Header{{Name: "../evil", Typeflag: tar.TypeReg}}, `unsafe path "../evil" escapes`},
+		{"absolute path", []tar.Header{{Name: "/tmp/evil", Typeflag: tar.TypeReg}}, `unsafe path "/tmp/evil"`},
+		{"absolute symlink", []tar.Header{{Name: "link", Linkname: "/etc", Typeflag: tar.TypeSymlink}}, "unsafe symlink link to /etc"},
+		{"symlink to parent", []tar.Header{{Name: "d/link", Linkname: "../..", Typeflag: tar.TypeSymlink}}, "unsafe symlink d/link to ../.."},
+		{"file through symlink", []tar.Header{
+			{Name: "inner", Linkname: ".", Typeflag: tar.TypeSymlink},
+			{Name: "inner/evil", Typeflag: tar.TypeReg},
+		}, "goes through symlink"},
+		{"symlink to sibling through parent", []tar.Header{{Name: "d/link", Linkname: "../f", Typeflag: tar.TypeSymlink}}, "unsafe symlink d/link to ../f"},
+		{"chained symlinks", []tar.Header{
+			{Name: "d", Linkname: ".", Typeflag: tar.TypeSymlink},
+			{Name: "a", Linkname: "d/d/d/../../..", Typeflag: tar.TypeSymlink},
+		}, "unsafe symlink a to d/d/d/../../.."},
+		{"hard link", []tar.Header{{Name: "hard", Linkname: "f", Typeflag: tar.TypeLink}}, "unsupported entry type"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := &bytes.Buffer{}
+			tw := tar.NewWriter(archive)
+			for _, header := range tt.headers {
+				header.Mode = 0644
+				if err := tw.WriteHeader(&header); err != nil {
+					t.Fatalf("WriteHeader() error = %v", err)
+				}
+			}
+			tw.Close()
+			root := filepath.Join(dir, strings.Repeat("r", i+1))
+			err := extractTar(archive, root)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("extractTar() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err = os.Lstat(filepath.Join(dir, "evil")); err == nil {
+		t.Errorf("file outside of root was created")
+	}
+	if _, err = os.Lstat(filepath.Join(dir, "rrrrrrr", "a")); err == nil {
+		t.Errorf("symlink escaping root was created")
+	}
+}
+
+func mustGetwd(t *testing.T) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("cannot get working directory: %v", err)
+	}
+	return wd
+}
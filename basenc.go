@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// basencEncoding is an encoding selectable by GNU basenc compatible flag
+type basencEncoding struct {
+	name   string
+	usage  string
+	encode func(input io.Reader, output io.Writer, wrapAfter uint) error
+	decode func(input io.Reader, output io.Writer, ignoreGarbage bool) error
+}
+
+var basencEncodings = []basencEncoding{
+	{
+		"base64", "same as 'base64' program (RFC4648 section 4)",
+		func(input io.Reader, output io.Writer, wrapAfter uint) error {
+			return xbase.Encode64(input, output, base64.StdEncoding, wrapAfter)
+		},
+		func(input io.Reader, output io.Writer, ignoreGarbage bool) error {
+			return xbase.Decode64(input, output, base64.StdEncoding, ignoreGarbage)
+		},
+	},
+	{
+		"base64url", "file- and url-safe base64 (RFC4648 section 5)",
+		func(input io.Reader, output io.Writer, wrapAfter uint) error {
+			return xbase.Encode64(input, output, base64.URLEncoding, wrapAfter)
+		},
+		func(input io.Reader, output io.Writer, ignoreGarbage bool) error {
+			return xbase.Decode64(input, output, base64.URLEncoding, ignoreGarbage)
+		},
+	},
+	{
+		"base32", "same as 'base32' program (RFC4648 section 6)",
+		func(input io.Reader, output io.Writer, wrapAfter uint) error {
+			return xbase.Encode32(input, output, base32.StdEncoding, wrapAfter)
+		},
+		func(input io.Reader, output io.Writer, ignoreGarbage bool) error {
+			return xbase.Decode32(input, output, base32.StdEncoding, ignoreGarbage)
+		},
+	},
+	{
+		"base32hex", "extended hex alphabet base32 (RFC4648 section 7)",
+		func(input io.Reader, output io.Writer, wrapAfter uint) error {
+			return xbase.Encode32(input, output, base32.HexEncoding, wrapAfter)
+		},
+		func(input io.Reader, output io.Writer, ignoreGarbage bool) error {
+			return xbase.Decode32(input, output, base32.HexEncoding, ignoreGarbage)
+		},
+	},
+	{"base16", "hex encoding (RFC4648 section 8)", xbase.Encode16, xbase.Decode16},
+	{
+		"base2msbf", "bit string with most significant bit (msb) first",
+		func(input io.Reader, output io.Writer, wrapAfter uint) error {
+			return xbase.EncodeBase2(input, output, false, wrapAfter)
+		},
+		func(input io.Reader, output io.Writer, ignoreGarbage bool) error {
+			return xbase.DecodeBase2(input, output, false, ignoreGarbage)
+		},
+	},
+	{
+		"base2lsbf", "bit string with least significant bit (lsb) first",
+		func(input io.Reader, output io.Writer, wrapAfter uint) error {
+			return xbase.EncodeBase2(input, output, true, wrapAfter)
+		},
+		func(input io.Reader, output io.Writer, ignoreGarbage bool) error {
+			return xbase.DecodeBase2(input, output, true, ignoreGarbage)
+		},
+	},
+	{
+		"z85", "ascii85-like encoding (ZeroMQ spec:32/Z85);\nwhen encoding, input length must be a multiple of 4;\nwhen decoding, input length must be a multiple of 5",
+		xbase.EncodeZ85, xbase.DecodeZ85,
+	},
+}
+
+// getBasencEncoding return encoding with name
+func getBasencEncoding(name string) (basencEncoding, bool) {
+	for _, e := range basencEncodings {
+		if e.name == name {
+			return e, true
+		}
+	}
+	return basencEncoding{}, false
+}
+
+// choiceFlag set selected to name when given, so when more flags of the same
+// choice are given the last one wins as with GNU basenc encoding flags,
+// --name=false clears the choice when name is selected
+type choiceFlag struct {
+	name     string
+	selected *string
+}
+
+func (f *choiceFlag) String() string { return "false" }
+func (f *choiceFlag) Type() string   { return "bool" }
+
+func (f *choiceFlag) Set(value string) error {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	switch {
+	case enabled:
+		*f.selected = f.name
+	case *f.selected == f.name:
+		*f.selected = ""
+	}
+	return nil
+}
+
+// choiceVar define boolean flag which set selected to name
+func choiceVar(flags *flag.FlagSet, selected *string, name, flagName, usage string) {
+	flags.Var(&choiceFlag{name: name, selected: selected}, flagName, usage)
+	flags.Lookup(flagName).NoOptDefVal = "true"
+}
+
+// runBasenc behave as GNU basenc, it is used when the program is invoked as
+// basenc or by basenc command
+func runBasenc(usageName string, args []string) error {
+	flags := flag.NewFlagSet(usageName, flag.ContinueOnError)
+	flags.SortFlags = false
+	var selected string
+	for _, e := range basencEncodings {
+		choiceVar(flags, &selected, e.name, e.name, e.usage)
+	}
+	var (
+		decode        = flags.BoolP("decode", "d", false, "decode data")
+		ignoreGarbage = flags.BoolP("ignore-garbage", "i", false, "when decoding, ignore non-alphabet characters")
+		wrapAfter     = flags.UintP("wrap", "w", 76, "wrap encoded lines after COLS character,\nuse 0 to disable line wrapping")
+		showVersion   = flags.BoolP("version", "v", false, "output version information and exit")
+		help          = flags.BoolP("help", "h", false, "print this help")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *help {
+		printBasencHelp(usageName, flags)
+		return nil
+	}
+
+	if *showVersion {
+		printVersion(os.Stdout)
+		return nil
+	}
+
+	encoding, ok := getBasencEncoding(selected)
+	if !ok {
+		return fmt.Errorf("missing encoding type\nTry '%s --help' for more information.", usageName)
+	}
+
+	file, err := getFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if !*decode {
+		if err = encoding.encode(file, os.Stdout, *wrapAfter); err != nil {
+			return fmt.Errorf("encode pipeline error: %v", err)
+		}
+		return nil
+	}
+	if err = encoding.decode(file, os.Stdout, *ignoreGarbage); err != nil {
+		return fmt.Errorf("decode pipeline error: %v", err)
+	}
+	return nil
+}
+
+func runBasencCommand(programName string, args []string) error {
+	return runBasenc(programName+" basenc", args)
+}
+
+func printBasencHelp(usageName string, flags *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: %s [OPTION]... [FILE]\n", usageName)
+	fmt.Fprintf(os.Stderr, `
+basenc encode or decode FILE, or standard input, to standard output.
+With no FILE, or when FILE is -, read standard input.
+
+`)
+	flags.PrintDefaults()
+	fmt.Fprintf(os.Stderr, `
+When decoding, the input may contain newlines in addition to the bytes of
+the formal alphabet.  Use --ignore-garbage to attempt to recover
+from any other non-alphabet bytes in the encoded stream.
+`)
+}
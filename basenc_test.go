@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	flag "github.com/spf13/pflag"
+)
+
+func Test_choiceVar(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantSelected string
+	}{
+		{"no encoding", []string{"file"}, ""},
+		{"single encoding", []string{"--base32", "file"}, "base32"},
+		{"last encoding wins", []string{"--base32", "--z85", "--base16"}, "base16"},
+		{"encoding after other flags", []string{"-d", "--base64url"}, "base64url"},
+		{"explicit true", []string{"--base32=true"}, "base32"},
+		{"false does not select", []string{"--base64=false"}, ""},
+		{"false clears selection", []string{"--base32", "--base32=false"}, ""},
+		{"false keeps other selection", []string{"--base16", "--base32=false"}, "base16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags.BoolP("decode", "d", false, "decode data")
+			var selected string
+			for _, e := range basencEncodings {
+				choiceVar(flags, &selected, e.name, e.name, e.usage)
+			}
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("Parse(%v) error = %v", tt.args, err)
+			}
+			if selected != tt.wantSelected {
+				t.Errorf("selected = %q, want %q", selected, tt.wantSelected)
+			}
+			if _, ok := getBasencEncoding(selected); ok != (tt.wantSelected != "") {
+				t.Errorf("getBasencEncoding(%q) ok = %v", selected, ok)
+			}
+		})
+	}
+}
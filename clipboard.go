@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// clipboard is the system clipboard
+type clipboard interface {
+	read() ([]byte, error)
+	write(data []byte) error
+}
+
+// newClipboard return clipboard of the system, tests replace it with a fake
+var newClipboard = detectClipboard
+
+// commandClipboard access clipboard by external commands
+type commandClipboard struct {
+	readCmd  []string
+	writeCmd []string
+}
+
+// clipboardCommands are tried in order, Wayland commands are preferred only
+// in Wayland session as X11 commands may work there too through XWayland
+var clipboardCommands = []commandClipboard{
+	{[]string{"wl-paste", "--no-newline"}, []string{"wl-copy"}},
+	{[]string{"xclip", "-selection", "clipboard", "-out"}, []string{"xclip", "-selection", "clipboard", "-in"}},
+	{[]string{"xsel", "--clipboard", "--output"}, []string{"xsel", "--clipboard", "--input"}},
+	{[]string{"pbpaste"}, []string{"pbcopy"}},
+}
+
+// detectClipboard return the first clipboard whose commands are available
+func detectClipboard() (clipboard, error) {
+	var names []string
+	for _, c := range clipboardCommands {
+		if c.readCmd[0] == "wl-paste" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			continue
+		}
+		names = append(names, c.writeCmd[0])
+		if _, err := exec.LookPath(c.readCmd[0]); err != nil {
+			continue
+		}
+		if _, err := exec.LookPath(c.writeCmd[0]); err != nil {
+			continue
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("no clipboard command found, install one of %s", strings.Join(names, ", "))
+}
+
+func (c commandClipboard) read() ([]byte, error) {
+	stderr := &bytes.Buffer{}
+	cmd := exec.Command(c.readCmd[0], c.readCmd[1:]...) // #nosec G204 -- fixed commands
+	cmd.Stderr = stderr
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read clipboard with %s: %v %s", c.readCmd[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return data, nil
+}
+
+func (c commandClipboard) write(data []byte) error {
+	stderr := &bytes.Buffer{}
+	cmd := exec.Command(c.writeCmd[0], c.writeCmd[1:]...) // #nosec G204 -- fixed commands
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("cannot write clipboard with %s: %v %s", c.writeCmd[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// openInput return string of --string, tar archive of FILEs with --archive,
+// content of clipboard with --from-clipboard, otherwise fileName or standard
+// input
+func openInput(opts *codecOptions, fileName string) (io.ReadCloser, error) {
+	if opts.literal != nil {
+		return ioutil.NopCloser(strings.NewReader(*opts.literal)), nil
+	}
+	if opts.archive != "" {
+		if opts.fromClipboard {
+			return nil, fmt.Errorf("--archive cannot be used with --from-clipboard")
+		}
+		paths := opts.paths
+		if len(paths) == 0 && fileName != "" {
+			paths = []string{fileName}
+		}
+		return tarReader(paths), nil
+	}
+	if !opts.fromClipboard {
+		return getFile(fileName)
+	}
+	if fileName != "" {
+		return nil, fmt.Errorf("--from-clipboard cannot be used with FILE %s", fileName)
+	}
+	c, err := newClipboard()
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+// openOutput return destination of output with --newline policy applied,
+// destination is opened by openDestination unless it was opened already
+func openOutput(opts *codecOptions) (stdout io.Writer, flush func() error, err error) {
+	stdout, flush = opts.destination, func() error { return nil }
+	if stdout == nil {
+		if stdout, flush, err = openDestination(opts); err != nil {
+			return nil, nil, err
+		}
+	}
+	if opts.newline == "" || opts.newline == "auto" {
+		return stdout, flush, nil
+	}
+	nw, err := newNewlineWriter(stdout, opts.newline)
+	if err != nil {
+		return nil, nil, err
+	}
+	flushOutput := flush
+	return nw, func() error {
+		if err := nw.Close(); err != nil {
+			return fmt.Errorf("cannot write to output: %v", err)
+		}
+		return flushOutput()
+	}, nil
+}
+
+// openDestination return standard output, --output FILE closed by flush or,
+// with --to-clipboard, buffer which is written to clipboard by flush
+func openDestination(opts *codecOptions) (stdout io.Writer, flush func() error, err error) {
+	stdout, flush = os.Stdout, func() error { return nil }
+	if opts.output != "" {
+		if opts.toClipboard {
+			return nil, nil, fmt.Errorf("--output cannot be used with --to-clipboard")
+		}
+		mode := os.O_RDWR | os.O_CREATE | os.O_TRUNC
+		if opts.resume {
+			mode = os.O_RDWR | os.O_CREATE
+		}
+		file, err := os.OpenFile(opts.output, mode, 0666) // #nosec G302 G304 -- like shell redirection
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot open output: %v", err)
+		}
+		stdout, flush = file, file.Close
+	}
+	if opts.toClipboard {
+		c, err := newClipboard()
+		if err != nil {
+			return nil, nil, err
+		}
+		buffer := &bytes.Buffer{}
+		stdout, flush = buffer, func() error { return c.write(buffer.Bytes()) }
+	}
+	return stdout, flush, nil
+}
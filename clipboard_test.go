@@ -0,0 +1,103 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeClipboard keeps clipboard content in memory
+type fakeClipboard struct {
+	content []byte
+}
+
+func (c *fakeClipboard) read() ([]byte, error) { return c.content, nil }
+
+func (c *fakeClipboard) write(data []byte) error {
+	c.content = append([]byte{}, data...)
+	return nil
+}
+
+func Test_runCodecWithClipboard(t *testing.T) {
+	fake := &fakeClipboard{}
+	defer func(original func() (clipboard, error)) { newClipboard = original }(newClipboard)
+	newClipboard = func() (clipboard, error) { return fake, nil }
+
+	tests := []struct {
+		name  string
+		opts  codecOptions
+		input string
+		want  string
+	}{
+		{"encode", codecOptions{wrapAfter: 76}, "hello", "aGVsbG8=\n"},
+		{"decode", codecOptions{decode: true}, "aGVsbG8=\n", "hello"},
+		{"decode to hexdump", codecOptions{decode: true, hexdump: true}, "aGk=", "00000000  68 69                                             |hi|\n00000002\n"},
+		{"transcode", codecOptions{from: "base64", to: "base16", wrapAfter: 76}, "aGk=", "6869\n"},
+		{"encode lines", codecOptions{lines: true}, "a\nb\n", "YQ==\nYg==\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake.content = []byte(tt.input)
+			opts := tt.opts
+			opts.fromClipboard, opts.toClipboard = true, true
+			if err := runCodec(&opts, ""); err != nil {
+				t.Fatalf("runCodec() error = %v", err)
+			}
+			if got := string(fake.content); got != tt.want {
+				t.Errorf("clipboard = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	fake.content = []byte("not base64!")
+	if err := runCodec(&codecOptions{decode: true, fromClipboard: true, toClipboard: true}, ""); err == nil {
+		t.Errorf("runCodec() with invalid input error = nil")
+	}
+	if string(fake.content) != "not base64!" {
+		t.Errorf("clipboard = %q, want it unchanged after error", fake.content)
+	}
+	if err := runCodec(&codecOptions{fromClipboard: true}, "file"); err == nil {
+		t.Errorf("runCodec() with --from-clipboard and FILE error = nil")
+	}
+}
+
+func Test_commandClipboard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clipboard")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+	file := filepath.Join(dir, "clipboard")
+
+	c := commandClipboard{
+		readCmd:  []string{"cat", file},
+		writeCmd: []string{"sh", "-c", "cat > " + file},
+	}
+	if err = c.write([]byte("token")); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	got, err := c.read()
+	if err != nil {
+		t.Fatalf("read() error = %v", err)
+	}
+	if string(got) != "token" {
+		t.Errorf("read() = %q, want %q", got, "token")
+	}
+
+	broken := commandClipboard{readCmd: []string{"false"}, writeCmd: []string{"false"}}
+	if _, err = broken.read(); err == nil {
+		t.Errorf("read() with failing command error = nil")
+	}
+	if err = broken.write(nil); err == nil {
+		t.Errorf("write() with failing command error = nil")
+	}
+}
+
+func Test_detectClipboard(t *testing.T) {
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", "")
+	if _, err := detectClipboard(); err == nil {
+		t.Errorf("detectClipboard() without commands error = nil")
+	}
+}
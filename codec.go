@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// codecOptions are flags shared by the default command, enc and dec
+type codecOptions struct {
+	decode         bool
+	ignoreGarbage  bool
+	concatenated   bool
+	noPadding      bool
+	url            bool
+	jsonPath       string
+	lines          bool
+	zeroTerminated bool
+	skipInvalid    bool
+	hexdump        bool
+	force          bool
+	compression    string
+	digest         string
+	digestFormat   string
+	digestFile     string
+	expectDigest   string
+	encrypt        bool
+	decrypt        bool
+	passphraseFile string
+	fromClipboard  bool
+	toClipboard    bool
+	strings        []string
+	newline        string
+	literal        *string   // one of strings processed by runCodec
+	destination    io.Writer // output opened once for all strings
+	recursive      string
+	archive        string
+	paths          []string // all FILE arguments, archived with --archive
+	extract        string
+	splitSize      uint
+	qr             bool
+	qrPNG          string
+	output         string
+	resume         bool
+	join           bool
+	offset         int64
+	length         int64
+	from           string
+	to             string
+	wrapAfter      uint
+}
+
+// registerCodecFlags define codecOptions on flags, options which make sense
+// only for encoding or only for decoding are defined when encode or decode is set
+func registerCodecFlags(flags *flag.FlagSet, encode, decode bool) *codecOptions {
+	opts := &codecOptions{}
+	flags.BoolVarP(&opts.noPadding, "no-padding", "n", false, "omit padding")
+	flags.BoolVarP(&opts.url, "url", "u", false, "use URL encoding according RFC4648")
+	flags.StringVar(&opts.jsonPath, "json-path", "", "encode or decode only string values selected by PATH\nin JSON documents or NDJSON (e.g. .data.*)")
+	flags.BoolVar(&opts.lines, "lines", false, "encode or decode every input line independently,\none output line per input line")
+	flags.BoolVarP(&opts.zeroTerminated, "zero-terminated", "z", false, "with --lines, line delimiter is NUL, not newline")
+	choiceVar(flags, &opts.compression, "gzip", "gzip", "compress data with gzip before encoding,\ndecompress them after decoding")
+	choiceVar(flags, &opts.compression, "zlib", "zlib", "compress data with zlib before encoding,\ndecompress them after decoding")
+	choiceVar(flags, &opts.compression, "deflate", "deflate", "compress data with raw deflate before encoding,\ndecompress them after decoding")
+	flags.StringVar(&opts.digest, "digest", "", "compute digest of raw data with `ALG` (sha256, sha384,\nsha512 or blake2b) and print it to standard error")
+	flags.StringVar(&opts.digestFormat, "digest-format", "sum", "print digest in `FORMAT` sum (like sha256sum) or sri\n(Subresource Integrity, e.g. sha384-...)")
+	flags.StringVar(&opts.digestFile, "digest-file", "", "write digest to `FILE` instead of standard error")
+	flags.StringArrayVarP(&opts.strings, "string", "s", nil, "encode or decode `STRING` instead of FILE, can be\nrepeated to process more strings independently")
+	flags.StringVar(&opts.newline, "newline", "auto", "end output with newline: always, never or auto (as\nGNU base64, decoded strings end with newline on\nterminal or when more strings are given)")
+	flags.StringVar(&opts.recursive, "recursive", "", "encode files of directory `DIR` to JSON manifest,\nwhen decoding restore the tree from manifest to DIR")
+	flags.StringVarP(&opts.output, "output", "o", "", "write output to `FILE` instead of standard output")
+	flags.BoolVar(&opts.fromClipboard, "from-clipboard", false, "read input from clipboard instead of FILE")
+	flags.BoolVar(&opts.toClipboard, "to-clipboard", false, "write output to clipboard instead of standard output")
+	flags.StringVar(&opts.passphraseFile, "passphrase-file", "", "with --encrypt or --decrypt, read passphrase from the\nfirst line of `FILE`")
+	if encode {
+		flags.UintVarP(&opts.wrapAfter, "wrap", "w", 76, "wrap encoded lines after COLS character,\nuse 0 to disable line wrapping")
+		flags.BoolVar(&opts.encrypt, "encrypt", false, "encrypt data with passphrase before encoding")
+		flags.UintVar(&opts.splitSize, "split-size", 0, "split encoded output into parts of at most `N` bytes\nwith \"part i/n\" headers and checksum of all parts")
+		flags.BoolVar(&opts.qr, "qr", false, "write encoded output as QR codes drawn by Unicode\nhalf blocks, split to more codes by --split-size\n(600 by default)")
+		flags.StringVar(&opts.qrPNG, "qr-png", "", "write encoded output as QR code to PNG `FILE`, more\ncodes to files numbered before extension (key-1.png)")
+		flags.StringVar(&opts.archive, "archive", "", "encode archive of all FILEs (files or directories)\nin `FORMAT` tar, combine with --gzip for tar.gz")
+	}
+	if encode && decode {
+		flags.StringVar(&opts.from, "from", "", "transcode input encoded by `ENC` (base64, base64url, base32,\nbase32hex, base16, base2msbf, base2lsbf, z85, base58),\nbase64 when only --to is given")
+		flags.StringVar(&opts.to, "to", "", "transcode input to `ENC`, base64 when only --from is given")
+	}
+	if decode {
+		flags.BoolVarP(&opts.ignoreGarbage, "ignore-garbage", "i", false, "when decoding, ignore non-alphabet characters")
+		flags.BoolVar(&opts.concatenated, "concatenated", false, "when decoding, accept independently padded segments\nconcatenated back to back (e.g. YQ==Yg==)")
+		flags.BoolVar(&opts.skipInvalid, "skip-invalid", false, "with --lines, when decoding, report and skip invalid lines")
+		flags.BoolVar(&opts.hexdump, "hexdump", false, "when decoding, output canonical hex+ASCII dump")
+		flags.BoolVar(&opts.force, "force", false, "when decoding, write binary data to terminal")
+		flags.BoolVar(&opts.decrypt, "decrypt", false, "when decoding, decrypt data encoded with --encrypt")
+		flags.StringVar(&opts.expectDigest, "expect-digest", "", "when decoding, fail unless digest of decoded data is\n`DIGEST` given as hex or as SRI string")
+		flags.BoolVar(&opts.join, "join", false, "when decoding, join parts written by --split-size given\nin any order, check that all are present and checksum")
+		flags.BoolVar(&opts.resume, "resume", false, "when decoding to --output FILE, continue after data\nalready in FILE, e.g. after interrupted decoding")
+		flags.Int64Var(&opts.offset, "offset", 0, "when decoding FILE, output decoded data from byte `N`,\nonly the range is decoded when FILE is wrapped regularly")
+		flags.Int64Var(&opts.length, "length", 0, "with --offset, output at most `N` decoded bytes,\n0 means up to the end")
+		flags.StringVar(&opts.extract, "extract", "", "when decoding, extract decoded tar archive to `DIR`\ninstead of writing it to standard output")
+		choiceVar(flags, &opts.compression, "auto", "auto-decompress", "when decoding, decompress data starting with gzip magic")
+	}
+	return opts
+}
+
+// runCodec encode or decode fileName, or standard input, to standard output
+func runCodec(opts *codecOptions, fileName string) (err error) {
+	if len(opts.strings) > 0 {
+		return runCodecStrings(opts, fileName)
+	}
+	if opts.recursive != "" {
+		return runRecursive(opts, fileName)
+	}
+	if opts.from != "" || opts.to != "" {
+		if opts.from == "" {
+			opts.from = "base64"
+		}
+		if opts.to == "" {
+			opts.to = "base64"
+		}
+		return runTranscode(opts, fileName)
+	}
+
+	encoding := getEncoding(opts.noPadding, opts.url)
+
+	if opts.hexdump && !opts.decode {
+		return fmt.Errorf("--hexdump can be used only when decoding")
+	}
+	if opts.hexdump && opts.jsonPath != "" {
+		return fmt.Errorf("--hexdump cannot be used with --json-path")
+	}
+	if opts.archive != "" || opts.extract != "" {
+		switch {
+		case opts.archive != "" && opts.decode:
+			return fmt.Errorf("--archive can be used only when encoding, use --extract DIR")
+		case opts.extract != "" && !opts.decode:
+			return fmt.Errorf("--extract can be used only when decoding, use --archive tar")
+		case opts.archive != "" && opts.archive != "tar":
+			return fmt.Errorf("unsupported archive format %q, use tar", opts.archive)
+		case opts.jsonPath != "" || opts.lines || opts.hexdump:
+			return fmt.Errorf("--archive and --extract cannot be used with --json-path, --lines or --hexdump")
+		case opts.extract != "" && opts.toClipboard:
+			return fmt.Errorf("--extract cannot be used with --to-clipboard")
+		}
+	}
+	if (opts.splitSize > 0 || opts.join || opts.qr || opts.qrPNG != "") && (opts.jsonPath != "" || opts.lines) {
+		return fmt.Errorf("--split-size, --join and QR codes cannot be used with --json-path or --lines")
+	}
+	if opts.resume {
+		switch {
+		case !opts.decode || opts.output == "":
+			return fmt.Errorf("--resume can be used only when decoding to --output FILE")
+		case opts.ignoreGarbage || opts.concatenated || opts.lines || opts.jsonPath != "" || opts.hexdump || opts.join:
+			return fmt.Errorf("--resume cannot be used with --ignore-garbage, --concatenated, --lines, --json-path, --hexdump or --join")
+		case opts.compression != "" || opts.decrypt || opts.digest != "" || opts.expectDigest != "" || opts.extract != "":
+			return fmt.Errorf("--resume cannot be used with compression, encryption, digest or --extract")
+		case opts.newline != "" && opts.newline != "auto":
+			return fmt.Errorf("--resume cannot be used with --newline")
+		}
+	}
+	if opts.offset != 0 || opts.length != 0 {
+		switch {
+		case opts.offset < 0 || opts.length < 0:
+			return fmt.Errorf("--offset and --length cannot be negative")
+		case !opts.decode:
+			return fmt.Errorf("--offset and --length can be used only when decoding")
+		case opts.ignoreGarbage || opts.concatenated || opts.lines || opts.jsonPath != "" || opts.join || opts.resume:
+			return fmt.Errorf("--offset and --length cannot be used with --ignore-garbage, --concatenated, --lines, --json-path, --join or --resume")
+		case opts.compression != "" || opts.decrypt || opts.extract != "":
+			return fmt.Errorf("--offset and --length cannot be used with compression, encryption or --extract")
+		}
+	}
+	if (opts.qr || opts.qrPNG != "") && opts.decode {
+		return fmt.Errorf("--qr and --qr-png can be used only when encoding")
+	}
+	if opts.compression == "auto" && !opts.decode {
+		return fmt.Errorf("--auto-decompress can be used only when decoding")
+	}
+	if opts.compression != "" && (opts.jsonPath != "" || opts.lines) {
+		return fmt.Errorf("compression cannot be used with --json-path or --lines")
+	}
+	var digest *digester
+	if opts.digest != "" || opts.expectDigest != "" {
+		if opts.jsonPath != "" || opts.lines {
+			return fmt.Errorf("digest cannot be used with --json-path or --lines")
+		}
+		if digest, err = newDigester(opts.digest, opts.digestFormat, opts.expectDigest); err != nil {
+			return err
+		}
+	}
+
+	var passphrase []byte
+	if opts.encrypt || opts.decrypt {
+		switch {
+		case opts.jsonPath != "" || opts.lines:
+			return fmt.Errorf("encryption cannot be used with --json-path or --lines")
+		case opts.encrypt && opts.decode:
+			return fmt.Errorf("--encrypt cannot be used when decoding, use --decrypt")
+		case opts.decrypt && !opts.decode:
+			return fmt.Errorf("--decrypt can be used only when decoding, use --encrypt")
+		}
+		if passphrase, err = getPassphrase(opts.passphraseFile, opts.encrypt); err != nil {
+			return err
+		}
+	}
+
+	stdout, flush, err := openOutput(opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = flush()
+		}
+	}()
+	var parts io.WriteCloser
+	switch {
+	case opts.qr || opts.qrPNG != "":
+		size := opts.splitSize
+		if size == 0 {
+			size = defaultQRPartSize
+		}
+		parts = &qrWriter{size: size, pngName: opts.qrPNG, w: stdout}
+	case opts.splitSize > 0 && !opts.decode:
+		parts = &splitWriter{size: opts.splitSize, w: stdout}
+	}
+	if parts != nil {
+		defer func() {
+			if err == nil {
+				err = parts.Close()
+			}
+		}()
+		stdout = parts
+	}
+
+	file, err := openInput(opts, fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var output io.WriteCloser
+	if opts.decode && opts.extract != "" {
+		output = extractWriter(opts.extract)
+	} else if output, err = getDecodeOutput(opts, stdout); err != nil {
+		return err
+	}
+	if opts.resume {
+		// deferred Close of output checks that all expected bytes came
+		if output, err = resumeDecoding(file, stdout, output); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		if cerr := output.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("decode pipeline error: %v", cerr)
+		}
+	}()
+
+	var (
+		input   io.Reader = file
+		decoded io.Writer = output
+		sniffer *newlineSniffer
+	)
+	if opts.join {
+		var joined []byte
+		if joined, err = joinParts(file); err != nil {
+			return fmt.Errorf("cannot join parts: %v", err)
+		}
+		input = bytes.NewReader(joined)
+	}
+	if !opts.decode && opts.literal == nil && !opts.fromClipboard && (fileName == "" || fileName == "-") && isTerminal(os.Stderr) {
+		sniffer = &newlineSniffer{r: input}
+		input = sniffer
+	}
+	if digest != nil {
+		// hash raw data, so before compression and after decompression
+		if opts.decode {
+			decoded = io.MultiWriter(decoded, digest)
+		} else {
+			input = io.TeeReader(input, digest)
+		}
+		defer func() {
+			if err == nil {
+				err = digest.finish(fileName, opts.digestFile, os.Stderr)
+			}
+		}()
+	}
+	if opts.compression != "" {
+		if !opts.decode {
+			compressed := compressReader(input, opts.compression)
+			defer compressed.Close()
+			input = compressed
+		} else {
+			decompressed := decompressWriter(decoded, opts.compression)
+			defer func() {
+				if cerr := decompressed.Close(); err == nil && cerr != nil {
+					err = fmt.Errorf("decode pipeline error: %v", cerr)
+				}
+			}()
+			decoded = decompressed
+		}
+	}
+	switch {
+	case opts.encrypt:
+		if input, err = encryptReader(input, passphrase); err != nil {
+			return fmt.Errorf("encode pipeline error: %v", err)
+		}
+	case opts.decrypt:
+		decrypted := &decryptingWriter{passphrase: passphrase, output: decoded}
+		defer func() {
+			if cerr := decrypted.Close(); err == nil && cerr != nil {
+				if _, ok := cerr.(authError); ok {
+					err = cerr
+					return
+				}
+				err = fmt.Errorf("decode pipeline error: %v", cerr)
+			}
+		}()
+		decoded = decrypted
+	}
+
+	switch {
+	case opts.jsonPath != "":
+		path, err := parseJSONPath(opts.jsonPath)
+		if err != nil {
+			return err
+		}
+		if err = transformJSON(file, stdout, path, jsonCodec(opts.decode, encoding, opts.ignoreGarbage)); err != nil {
+			return fmt.Errorf("JSON pipeline error: %v", err)
+		}
+	case opts.lines && !opts.decode:
+		if err = xbase.EncodeLines64(file, stdout, encoding, getDelimiter(opts.zeroTerminated)); err != nil {
+			return fmt.Errorf("encode pipeline error: %v", err)
+		}
+	case opts.lines && opts.decode:
+		var onInvalid func(*xbase.LineError) error
+		if opts.skipInvalid {
+			onInvalid = func(lerr *xbase.LineError) error {
+				fmt.Fprintf(os.Stderr, "skipping invalid %v\n", lerr)
+				return nil
+			}
+		}
+		if err = xbase.DecodeLines64(input, decoded, encoding, opts.ignoreGarbage, getDelimiter(opts.zeroTerminated), onInvalid); err != nil {
+			return fmt.Errorf("decode pipeline error: %v", err)
+		}
+	case !opts.decode: // encode
+		if err = xbase.Encode64(input, stdout, encoding, opts.wrapAfter); err != nil {
+			return fmt.Errorf("encode pipeline error: %v", err)
+		}
+		if sniffer != nil && sniffer.accidentalNewline() {
+			fmt.Fprintln(os.Stderr, "warning: input ends with newline, which is encoded too, use -s STRING or echo -n")
+		}
+	case opts.offset != 0 || opts.length != 0:
+		if err = decodeRange(file, decoded, encoding, opts.offset, opts.length); err != nil {
+			return fmt.Errorf("decode pipeline error: %v", err)
+		}
+	case opts.concatenated:
+		if err = xbase.Decode64Segments(input, decoded, encoding, opts.ignoreGarbage); err != nil {
+			return fmt.Errorf("decode pipeline error: %v", err)
+		}
+	default:
+		if err = xbase.Decode64(input, decoded, encoding, opts.ignoreGarbage); err != nil {
+			return fmt.Errorf("encode pipeline error: %v", err)
+		}
+	}
+	return nil
+}
+
+// getDecodeOutput return writer for decoded data, which is hexdump of data
+// with --hexdump and which refuses binary data on terminal without --force
+func getDecodeOutput(opts *codecOptions, stdout io.Writer) (io.WriteCloser, error) {
+	switch {
+	case !opts.decode || opts.jsonPath != "":
+		return nopCloser{stdout}, nil
+	case opts.hexdump:
+		return xbase.NewHexdumper(stdout), nil
+	case !opts.force && isTerminalOutput(stdout):
+		return &binaryGuard{w: stdout}, nil
+	}
+	return nopCloser{stdout}, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func getDelimiter(zeroTerminated bool) byte {
+	if zeroTerminated {
+		return 0
+	}
+	return '\n'
+}
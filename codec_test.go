@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func Test_getDelimiter(t *testing.T) {
+	tests := []struct {
+		name           string
+		zeroTerminated bool
+		want           byte
+	}{
+		{"newline by default", false, '\n'},
+		{"NUL for zero terminated", true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getDelimiter(tt.zeroTerminated); got != tt.want {
+				t.Errorf("getDelimiter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// command is selected by -c NAME or --command NAME as the first argument, GNU
+// base64 has no such option, so any other invocation keeps its behaviour
+type command struct {
+	name        string
+	description string
+	run         func(programName string, args []string) error
+}
+
+var commands = []command{
+	{"enc", "encode data", runEncode},
+	{"dec", "decode data", runDecode},
+	{"basenc", "encode or decode data as GNU basenc", runBasencCommand},
+	{"inspect", "analyse base64 input and print diagnostics", runInspect},
+	{"jwt", "inspect JWS (JWT) and optionally verify its signature", runJWT},
+	{"secret", "decode or encode data of Kubernetes Secret manifests", runSecret},
+	{"sri", "print or verify Subresource Integrity hashes", runSRI},
+	{"rand", "generate random tokens", runRand},
+	{"interactive", "encode or decode typed lines immediately", runInteractive},
+}
+
+// getCommand return command with name
+func getCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// parseCommand return command selected by -c NAME, --command NAME or
+// --command=NAME at the start of args and arguments following it, ok is false
+// when args do not select a command
+func parseCommand(args []string) (cmd command, rest []string, ok bool, err error) {
+	if len(args) == 0 {
+		return command{}, nil, false, nil
+	}
+	var name string
+	switch arg := args[0]; {
+	case arg == "-c" || arg == "--command":
+		if len(args) < 2 {
+			return command{}, nil, false, fmt.Errorf("option %s needs COMMAND", arg)
+		}
+		name, rest = args[1], args[2:]
+	case strings.HasPrefix(arg, "--command="):
+		name, rest = strings.TrimPrefix(arg, "--command="), args[1:]
+	default:
+		return command{}, nil, false, nil
+	}
+	if cmd, ok = getCommand(name); !ok {
+		return command{}, nil, false, fmt.Errorf("unknown command %q", name)
+	}
+	return cmd, rest, true, nil
+}
+
+func runEncode(programName string, args []string) error {
+	return runCodecCommand(programName, "enc", "Base64 encode FILE, or standard input, to standard output.", false, args)
+}
+
+func runDecode(programName string, args []string) error {
+	return runCodecCommand(programName, "dec", "Base64 decode FILE, or standard input, to standard output.", true, args)
+}
+
+// runCodecCommand run enc or dec command, which are the default command with
+// fixed direction and only relevant options
+func runCodecCommand(programName, name, description string, decode bool, args []string) error {
+	flags := flag.NewFlagSet(programName+" "+name, flag.ContinueOnError)
+	opts := registerCodecFlags(flags, !decode, decode)
+	help := flags.BoolP("help", "h", false, "print this help")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *help {
+		printCommandHelp(programName, name, description, flags)
+		return nil
+	}
+
+	opts.decode = decode
+	opts.paths = flags.Args()
+	return runCodec(opts, flags.Arg(0))
+}
+
+func printCommandHelp(programName, name, description string, flags *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: %s %s [OPTION]... [FILE]\n", programName, name)
+	fmt.Fprintf(os.Stderr, `
+%s
+With no FILE, or when FILE is -, read standard input.
+
+`, description)
+	flags.PrintDefaults()
+}
+
+func printCommands(programName string) {
+	fmt.Fprintf(os.Stderr, "\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintf(os.Stderr, `
+Run '%s -c COMMAND --help' for more information on a command.
+`, programName)
+}
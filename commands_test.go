@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_getCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg      string
+		wantName string
+		wantOK   bool
+	}{
+		{"encode command", "enc", "enc", true},
+		{"decode command", "dec", "dec", true},
+		{"basenc command", "basenc", "basenc", true},
+		{"inspect command", "inspect", "inspect", true},
+		{"jwt command", "jwt", "jwt", true},
+		{"secret command", "secret", "secret", true},
+		{"sri command", "sri", "sri", true},
+		{"rand command", "rand", "rand", true},
+		{"interactive command", "interactive", "interactive", true},
+		{"file name is not a command", "input.txt", "", false},
+		{"flag is not a command", "-d", "", false},
+		{"end of flags is not a command", "--", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCmd, gotOK := getCommand(tt.arg)
+			if gotOK != tt.wantOK {
+				t.Errorf("getCommand() ok = %v, want %v", gotOK, tt.wantOK)
+			}
+			if gotCmd.name != tt.wantName {
+				t.Errorf("getCommand() = %v, want %v", gotCmd.name, tt.wantName)
+			}
+		})
+	}
+}
+
+func Test_parseCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantName string
+		wantRest []string
+		wantOK   bool
+		wantErr  string
+	}{
+		{"short option", []string{"-c", "jwt", "--alg", "HS256"}, "jwt", []string{"--alg", "HS256"}, true, ""},
+		{"long option", []string{"--command", "sri", "app.js"}, "sri", []string{"app.js"}, true, ""},
+		{"long option with value", []string{"--command=rand", "-u"}, "rand", []string{"-u"}, true, ""},
+		{"file named like command", []string{"secret"}, "", nil, false, ""},
+		{"option of default command", []string{"-d", "-c", "jwt"}, "", nil, false, ""},
+		{"no arguments", nil, "", nil, false, ""},
+		{"missing command", []string{"-c"}, "", nil, false, "option -c needs COMMAND"},
+		{"unknown command", []string{"--command", "input.txt"}, "", nil, false, `unknown command "input.txt"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCmd, gotRest, gotOK, err := parseCommand(tt.args)
+			if (err == nil && tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("parseCommand() error = %v, want %q", err, tt.wantErr)
+			}
+			if gotOK != tt.wantOK {
+				t.Errorf("parseCommand() ok = %v, want %v", gotOK, tt.wantOK)
+			}
+			if gotCmd.name != tt.wantName {
+				t.Errorf("parseCommand() = %v, want %v", gotCmd.name, tt.wantName)
+			}
+			if strings.Join(gotRest, " ") != strings.Join(tt.wantRest, " ") {
+				t.Errorf("parseCommand() rest = %q, want %q", gotRest, tt.wantRest)
+			}
+		})
+	}
+}
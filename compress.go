@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// gzipMagic starts every gzip member (RFC1952)
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// newCompressor return writer which compress data written to it into output
+func newCompressor(compression string, output io.Writer) (io.WriteCloser, error) {
+	switch compression {
+	case "gzip":
+		return gzip.NewWriter(output), nil
+	case "zlib":
+		return zlib.NewWriter(output), nil
+	case "deflate":
+		return flate.NewWriter(output, flate.DefaultCompression)
+	}
+	return nil, fmt.Errorf("unsupported compression %q", compression)
+}
+
+// newDecompressor return reader of decompressed input, with auto compression
+// input is decompressed only when it starts with gzip magic
+func newDecompressor(compression string, input io.Reader) (io.ReadCloser, error) {
+	switch compression {
+	case "gzip":
+		return gzip.NewReader(input)
+	case "zlib":
+		return zlib.NewReader(input)
+	case "deflate":
+		return flate.NewReader(input), nil
+	case "auto":
+		r := bufio.NewReader(input)
+		if magic, _ := r.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+			return gzip.NewReader(r)
+		}
+		return ioutil.NopCloser(r), nil
+	}
+	return nil, fmt.Errorf("unsupported compression %q", compression)
+}
+
+// compressReader return reader of input compressed in background, so it can
+// be streamed into encoder
+func compressReader(input io.Reader, compression string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		compressor, err := newCompressor(compression, pw)
+		if err == nil {
+			_, err = io.Copy(compressor, input)
+			if cerr := compressor.Close(); err == nil {
+				err = cerr
+			}
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// decompressingWriter decompress data written to it into output in background
+type decompressingWriter struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+// decompressWriter return writer which decompress data written to it into
+// output, so it can be streamed from decoder, Close wait for all data to be
+// written to output
+func decompressWriter(output io.Writer, compression string) io.WriteCloser {
+	pr, pw := io.Pipe()
+	w := &decompressingWriter{pw: pw, done: make(chan error, 1)}
+	go func() {
+		// byte reader keeps decompressors from reading ahead, so trailing
+		// data stay in r
+		r := bufio.NewReader(pr)
+		decompressor, err := newDecompressor(compression, r)
+		if err == nil {
+			_, err = io.Copy(output, decompressor)
+			if cerr := decompressor.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err == nil {
+			// reject data after the end of compressed stream
+			if n, _ := io.Copy(ioutil.Discard, r); n > 0 {
+				err = fmt.Errorf("%d bytes of trailing data after %s stream", n, compression)
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("cannot decompress: %v", err)
+		}
+		pr.CloseWithError(err)
+		w.done <- err
+	}()
+	return w
+}
+
+func (w *decompressingWriter) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+func (w *decompressingWriter) Close() error {
+	w.pw.Close()
+	return <-w.done
+}
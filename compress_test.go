@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func Test_compressRoundTrip(t *testing.T) {
+	data := strings.Repeat("compress me, please ", 1000)
+	for _, compression := range []string{"gzip", "zlib", "deflate"} {
+		t.Run(compression, func(t *testing.T) {
+			compressed, err := ioutil.ReadAll(compressReader(strings.NewReader(data), compression))
+			if err != nil {
+				t.Fatalf("compressReader() error = %v", err)
+			}
+			if len(compressed) >= len(data) {
+				t.Errorf("compressed size = %d, want less than %d", len(compressed), len(data))
+			}
+
+			output := &bytes.Buffer{}
+			w := decompressWriter(output, compression)
+			// write in small chunks as decoder does
+			for chunk := compressed; len(chunk) > 0; {
+				n := 100
+				if n > len(chunk) {
+					n = len(chunk)
+				}
+				if _, err = w.Write(chunk[:n]); err != nil {
+					t.Fatalf("decompressWriter.Write() error = %v", err)
+				}
+				chunk = chunk[n:]
+			}
+			if err = w.Close(); err != nil {
+				t.Fatalf("decompressWriter.Close() error = %v", err)
+			}
+			if output.String() != data {
+				t.Errorf("decompressed data differ, got %d bytes, want %d bytes", output.Len(), len(data))
+			}
+		})
+	}
+}
+
+func Test_decompressWriter(t *testing.T) {
+	gzipped, err := ioutil.ReadAll(compressReader(strings.NewReader("hello"), "gzip"))
+	if err != nil {
+		t.Fatalf("compressReader() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		compression string
+		input       []byte
+		wantOutput  string
+		wantErr     bool
+	}{
+		{"auto with gzip", "auto", gzipped, "hello", false},
+		{"auto with plain data", "auto", []byte("hello"), "hello", false},
+		{"auto with single byte", "auto", []byte{0x1f}, "\x1f", false},
+		{"auto with empty data", "auto", nil, "", false},
+		{"gzip with plain data", "gzip", []byte("hello"), "", true},
+		{"truncated gzip", "gzip", gzipped[:len(gzipped)-4], "", true},
+		{"trailing data", "zlib", append(mustCompress(t, "zlib", "hello"), "garbage"...), "hello", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			w := decompressWriter(output, tt.compression)
+			_, err := w.Write(tt.input)
+			if cerr := w.Close(); err == nil {
+				err = cerr
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decompressWriter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && output.String() != tt.wantOutput {
+				t.Errorf("decompressWriter() output = %q, want %q", output.String(), tt.wantOutput)
+			}
+		})
+	}
+}
+
+func mustCompress(t *testing.T, compression, data string) []byte {
+	t.Helper()
+	compressed := &bytes.Buffer{}
+	if _, err := io.Copy(compressed, compressReader(strings.NewReader(data), compression)); err != nil {
+		t.Fatalf("compressReader() error = %v", err)
+	}
+	return compressed.Bytes()
+}
+
+func Test_runCodecAutoDecompressWhenEncoding(t *testing.T) {
+	literal := "hello"
+	err := runCodec(&codecOptions{literal: &literal, compression: "auto", wrapAfter: 76}, "")
+	if err == nil || !strings.Contains(err.Error(), "--auto-decompress can be used only when decoding") {
+		t.Errorf("runCodec() error = %v, want --auto-decompress error", err)
+	}
+}
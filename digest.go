@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// digestAlgorithms are hash functions selectable by --digest
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+	"blake2b": func() hash.Hash {
+		h, _ := blake2b.New512(nil) // fails only for too long key
+		return h
+	},
+}
+
+// digester hash raw data written to it and print or check the digest
+type digester struct {
+	hash.Hash
+	alg       string
+	format    string
+	print     bool   // print digest, it is not printed with --expect-digest only
+	expected  []byte // nil when digest is not checked
+	expectSRI bool   // expected digest was given as SRI string
+}
+
+// newDigester return digester for algorithm alg and format, expect is
+// expected digest as hex or as SRI string, when alg is empty it is taken from
+// SRI string or guessed from length of hex digest
+func newDigester(alg, format, expect string) (*digester, error) {
+	d := &digester{alg: alg, format: format, print: alg != ""}
+	if format != "sum" && format != "sri" {
+		return nil, fmt.Errorf("invalid digest format %q, use sum or sri", format)
+	}
+
+	if expect != "" {
+		var err error
+		if i := strings.IndexByte(expect, '-'); i > 0 && digestAlgorithms[expect[:i]] != nil {
+			d.expectSRI = true
+			if alg != "" && alg != expect[:i] {
+				return nil, fmt.Errorf("expected digest is %s, but --digest is %s", expect[:i], alg)
+			}
+			d.alg = expect[:i]
+			if d.expected, err = base64.StdEncoding.DecodeString(expect[i+1:]); err != nil {
+				return nil, fmt.Errorf("invalid expected digest %q: %v", expect, err)
+			}
+		} else {
+			if d.expected, err = hex.DecodeString(expect); err != nil {
+				return nil, fmt.Errorf("invalid expected digest %q: %v", expect, err)
+			}
+			if d.alg == "" {
+				d.alg = map[int]string{sha256.Size: "sha256", sha512.Size384: "sha384", sha512.Size: "sha512"}[len(d.expected)]
+			}
+		}
+	}
+
+	newHash, ok := digestAlgorithms[d.alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported digest algorithm %q, use sha256, sha384, sha512 or blake2b", d.alg)
+	}
+	if d.print && format == "sri" && sriStrength[d.alg] == 0 {
+		return nil, fmt.Errorf("%s digest cannot be printed in sri format", d.alg)
+	}
+	d.Hash = newHash()
+	if d.expected != nil && len(d.expected) != d.Size() {
+		return nil, fmt.Errorf("expected %s digest must be %d bytes, got %d", d.alg, d.Size(), len(d.expected))
+	}
+	return d, nil
+}
+
+// formatDigest format sum as line of sha256sum (and alike) output or as SRI
+func formatDigest(alg, format string, sum []byte, name string) string {
+	if format == "sri" {
+		return alg + "-" + base64.StdEncoding.EncodeToString(sum) + "\n"
+	}
+	if name == "" {
+		name = "-"
+	}
+	return fmt.Sprintf("%x  %s\n", sum, name)
+}
+
+// finish print digest of data named name to standard error or to digestFile
+// and check it against expected digest
+func (d *digester) finish(name, digestFile string, stderr io.Writer) error {
+	sum := d.Sum(nil)
+	if d.print {
+		if err := writeDigest(formatDigest(d.alg, d.format, sum, name), digestFile, stderr); err != nil {
+			return err
+		}
+	}
+	if d.expected != nil && !bytes.Equal(sum, d.expected) {
+		if d.expectSRI {
+			return fmt.Errorf("digest mismatch: decoded data have %s-%s, expected %s-%s", d.alg,
+				base64.StdEncoding.EncodeToString(sum), d.alg, base64.StdEncoding.EncodeToString(d.expected))
+		}
+		return fmt.Errorf("digest mismatch: decoded data have %s %x, expected %x", d.alg, sum, d.expected)
+	}
+	return nil
+}
+
+// writeDigest write line to digestFile or, without it, to stderr
+func writeDigest(line, digestFile string, stderr io.Writer) error {
+	if digestFile == "" {
+		_, err := io.WriteString(stderr, line)
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Clean(digestFile), []byte(line), 0644); err != nil {
+		return fmt.Errorf("cannot write digest: %v", err)
+	}
+	return nil
+}
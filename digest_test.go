@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func Test_newDigester(t *testing.T) {
+	const (
+		helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+		helloSHA384 = "sha384-WeF0h3dEjGnea4ANejO7+5/xtGPkQ1TDVTvNucZm+pASWjx5+QOXvfX2oT3oKGhP"
+	)
+	tests := []struct {
+		name     string
+		alg      string
+		format   string
+		expect   string
+		data     string
+		wantLine string
+		wantErr  bool
+		wantFail bool
+	}{
+		{"sha256 sum", "sha256", "sum", "", "hello", helloSHA256 + "  file\n", false, false},
+		{"sha384 sri", "sha384", "sri", "", "hello", helloSHA384 + "\n", false, false},
+		{"blake2b sum", "blake2b", "sum", "", "", "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce  file\n", false, false},
+		{"expected hex guesses algorithm", "", "sum", helloSHA256, "hello", "", false, false},
+		{"expected SRI selects algorithm", "", "sum", helloSHA384, "hello", "", false, false},
+		{"expected digest mismatch", "", "sum", helloSHA256, "hello!", "", false, true},
+		{"expected SRI mismatch", "", "sum", helloSHA384, "hello!", "", false, true},
+		{"expected digest with other algorithm", "sha512", "sum", helloSHA384, "", "", true, false},
+		{"expected digest of wrong length", "sha512", "sum", helloSHA256, "", "", true, false},
+		{"expected digest is not hex", "", "sum", "xyz", "", "", true, false},
+		{"unknown length of expected digest", "", "sum", "abcd", "", "", true, false},
+		{"unsupported algorithm", "md5", "sum", "", "", "", true, false},
+		{"blake2b in sri format", "blake2b", "sri", "", "", "", true, false},
+		{"invalid format", "sha256", "json", "", "", "", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newDigester(tt.alg, tt.format, tt.expect)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newDigester() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			io.WriteString(d, tt.data)
+			sum := d.Sum(nil)
+			if tt.wantLine != "" {
+				if gotLine := formatDigest(d.alg, d.format, sum, "file"); gotLine != tt.wantLine {
+					t.Errorf("formatDigest() = %q, want %q", gotLine, tt.wantLine)
+				}
+			}
+			if err = d.finish("file", "", ioutil.Discard); (err != nil) != tt.wantFail {
+				t.Errorf("finish() error = %v, wantFail %v", err, tt.wantFail)
+			}
+		})
+	}
+}
+
+func Test_writeDigest(t *testing.T) {
+	stderr := &strings.Builder{}
+	if err := writeDigest("line\n", "", stderr); err != nil {
+		t.Fatalf("writeDigest() error = %v", err)
+	}
+	if stderr.String() != "line\n" {
+		t.Errorf("writeDigest() stderr = %q, want %q", stderr.String(), "line\n")
+	}
+}
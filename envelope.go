@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Envelope is binary data encoded by --encrypt:
+//
+//	magic "B64ENC" | version | kdf | log2(N) | r | p | salt | aead | nonce | ciphertext
+//
+// everything before ciphertext is the header, it is authenticated together
+// with ciphertext
+const (
+	envelopeMagic   = "B64ENC"
+	envelopeVersion = 1
+	kdfScrypt       = 1
+	aeadAES256GCM   = 1
+	saltSize        = 16
+	keySize         = 32
+	headerSize      = len(envelopeMagic) + 5 + saltSize + 1 // without nonce
+
+	defaultScryptLogN = 15 // 32 MiB of memory with r = 8
+	maxScryptLogN     = 20 // refuse envelopes asking for more than 1 GiB
+	scryptR           = 8
+	scryptP           = 1
+
+	passphraseEnv = "BASE64_PASSPHRASE"
+)
+
+// authError is returned when envelope cannot be authenticated, main exits
+// with its own status so scripts can tell it apart from other failures
+type authError struct{}
+
+func (authError) Error() string {
+	return "authentication failed: wrong passphrase or corrupted data"
+}
+
+func (authError) ExitCode() int { return 3 }
+
+// sealEnvelope encrypt plaintext with key derived from passphrase by scrypt
+// with N = 2^logN
+func sealEnvelope(plaintext, passphrase []byte, logN byte) ([]byte, error) {
+	header := make([]byte, 0, headerSize)
+	header = append(header, envelopeMagic...)
+	header = append(header, envelopeVersion, kdfScrypt, logN, scryptR, scryptP)
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("cannot generate salt: %v", err)
+	}
+	header = append(header, salt...)
+	header = append(header, aeadAES256GCM)
+
+	aead, err := newEnvelopeAEAD(passphrase, salt, logN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("cannot generate nonce: %v", err)
+	}
+	header = append(header, nonce...)
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// openEnvelope decrypt envelope sealed by sealEnvelope, authError is returned
+// for wrong passphrase or modified envelope
+func openEnvelope(envelope, passphrase []byte) ([]byte, error) {
+	if len(envelope) < headerSize || !bytes.HasPrefix(envelope, []byte(envelopeMagic)) {
+		return nil, fmt.Errorf("input is not an encrypted envelope")
+	}
+	fields := envelope[len(envelopeMagic):]
+	version, kdf, logN, r, p := fields[0], fields[1], fields[2], fields[3], fields[4]
+	salt := fields[5 : 5+saltSize]
+	aeadID := fields[5+saltSize]
+	switch {
+	case version != envelopeVersion:
+		return nil, fmt.Errorf("unsupported envelope version %d", version)
+	case kdf != kdfScrypt:
+		return nil, fmt.Errorf("unsupported key derivation function %d", kdf)
+	case aeadID != aeadAES256GCM:
+		return nil, fmt.Errorf("unsupported cipher %d", aeadID)
+	case logN > maxScryptLogN || r == 0 || p == 0 || int(r)*int(p) > 64:
+		return nil, fmt.Errorf("refusing scrypt parameters N=2^%d, r=%d, p=%d", logN, r, p)
+	}
+
+	aead, err := newEnvelopeAEAD(passphrase, salt, logN, int(r), int(p))
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope) < headerSize+aead.NonceSize()+aead.Overhead() {
+		return nil, authError{}
+	}
+	header := envelope[:headerSize+aead.NonceSize()]
+	nonce := header[headerSize:]
+	plaintext, err := aead.Open(nil, nonce, envelope[len(header):], header)
+	if err != nil {
+		return nil, authError{}
+	}
+	return plaintext, nil
+}
+
+func newEnvelopeAEAD(passphrase, salt []byte, logN byte, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, 1<<logN, r, p, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("cannot derive key: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptReader return reader of envelope with all data from input
+func encryptReader(input io.Reader, passphrase []byte) (io.Reader, error) {
+	plaintext, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read from input: %v", err)
+	}
+	envelope, err := sealEnvelope(plaintext, passphrase, defaultScryptLogN)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(envelope), nil
+}
+
+// decryptingWriter collect envelope and write plaintext to output on Close,
+// so nothing is written before the whole envelope is authenticated
+type decryptingWriter struct {
+	envelope   bytes.Buffer
+	passphrase []byte
+	output     io.Writer
+}
+
+func (w *decryptingWriter) Write(p []byte) (int, error) {
+	return w.envelope.Write(p)
+}
+
+func (w *decryptingWriter) Close() error {
+	plaintext, err := openEnvelope(w.envelope.Bytes(), w.passphrase)
+	if err != nil {
+		return err
+	}
+	if _, err = w.output.Write(plaintext); err != nil {
+		return fmt.Errorf("cannot write to output: %v", err)
+	}
+	return nil
+}
+
+// getPassphrase read passphrase from the first line of passphraseFile, from
+// environment variable or ask for it on terminal, twice when confirm is set
+func getPassphrase(passphraseFile string, confirm bool) ([]byte, error) {
+	if passphraseFile != "" {
+		data, err := ioutil.ReadFile(filepath.Clean(passphraseFile))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read passphrase: %v", err)
+		}
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[:i]
+		}
+		return checkPassphrase(bytes.TrimSuffix(data, []byte("\r")))
+	}
+	if passphrase, ok := os.LookupEnv(passphraseEnv); ok {
+		return checkPassphrase([]byte(passphrase))
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot ask for passphrase, use --passphrase-file or %s: %v", passphraseEnv, err)
+	}
+	defer tty.Close()
+	passphrase, err := readPassword(tty, "Passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if confirm {
+		again, err := readPassword(tty, "Passphrase again: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+	return checkPassphrase(passphrase)
+}
+
+func readPassword(tty *os.File, prompt string) ([]byte, error) {
+	fmt.Fprint(tty, prompt)
+	defer fmt.Fprintln(tty)
+	passphrase, err := terminal.ReadPassword(int(tty.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read passphrase: %v", err)
+	}
+	return passphrase, nil
+}
+
+func checkPassphrase(passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase is empty")
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func Test_openEnvelope(t *testing.T) {
+	plaintext := []byte("top secret")
+	envelope, err := sealEnvelope(plaintext, []byte("passphrase"), 10)
+	if err != nil {
+		t.Fatalf("sealEnvelope() error = %v", err)
+	}
+	modify := func(i int, b byte) []byte {
+		modified := append([]byte{}, envelope...)
+		modified[i] ^= b
+		return modified
+	}
+
+	tests := []struct {
+		name       string
+		envelope   []byte
+		passphrase string
+		wantAuth   bool
+		wantErr    bool
+	}{
+		{"correct passphrase", envelope, "passphrase", false, false},
+		{"wrong passphrase", envelope, "Passphrase", true, true},
+		{"modified ciphertext", modify(len(envelope)-1, 1), "passphrase", true, true},
+		{"modified salt", modify(len(envelopeMagic)+5, 1), "passphrase", true, true},
+		{"truncated ciphertext", envelope[:headerSize+12+4], "passphrase", true, true},
+		{"not an envelope", []byte("hello"), "passphrase", false, true},
+		{"unsupported version", modify(len(envelopeMagic), 2), "passphrase", false, true},
+		{"too expensive scrypt", modify(len(envelopeMagic)+2, 0x10), "passphrase", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := openEnvelope(tt.envelope, []byte(tt.passphrase))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("openEnvelope() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, isAuth := err.(authError); isAuth != tt.wantAuth {
+				t.Errorf("openEnvelope() error = %v, want authError %v", err, tt.wantAuth)
+			}
+			if err == nil && !bytes.Equal(got, plaintext) {
+				t.Errorf("openEnvelope() = %q, want %q", got, plaintext)
+			}
+		})
+	}
+}
+
+func Test_decryptingWriter(t *testing.T) {
+	envelope, err := sealEnvelope([]byte("top secret"), []byte("passphrase"), 10)
+	if err != nil {
+		t.Fatalf("sealEnvelope() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		envelope   []byte
+		wantOutput string
+		wantErr    bool
+	}{
+		{"authenticated", envelope, "top secret", false},
+		{"nothing is written when authentication fails", envelope[:len(envelope)-1], "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			w := &decryptingWriter{passphrase: []byte("passphrase"), output: output}
+			for _, b := range tt.envelope {
+				w.Write([]byte{b})
+				if output.Len() > 0 {
+					t.Fatalf("decryptingWriter wrote %q before Close", output)
+				}
+			}
+			if err := w.Close(); (err != nil) != tt.wantErr {
+				t.Errorf("decryptingWriter.Close() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if output.String() != tt.wantOutput {
+				t.Errorf("decryptingWriter output = %q, want %q", output, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func Test_getPassphrase(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "passphrase")
+	if err != nil {
+		t.Fatalf("cannot create temporary file: %v", err)
+	}
+	defer os.Remove(tmpfile.Name()) // clean up
+	tmpfile.WriteString("from file\r\nsecond line\n")
+	tmpfile.Close()
+
+	got, err := getPassphrase(tmpfile.Name(), true)
+	if err != nil || string(got) != "from file" {
+		t.Errorf("getPassphrase(%s) = %q, %v, want %q", tmpfile.Name(), got, err, "from file")
+	}
+
+	os.Setenv(passphraseEnv, "from env")
+	defer os.Unsetenv(passphraseEnv)
+	got, err = getPassphrase("", true)
+	if err != nil || string(got) != "from env" {
+		t.Errorf("getPassphrase() = %q, %v, want %q", got, err, "from env")
+	}
+
+	os.Setenv(passphraseEnv, "")
+	if _, err = getPassphrase("", false); err == nil {
+		t.Errorf("getPassphrase() with empty passphrase error = nil")
+	}
+}
@@ -4,5 +4,11 @@ go 1.12
 
 require (
 	github.com/google/go-cmp v0.3.0
+	github.com/makiuchi-d/gozxing v0.0.0-20190830103442-eaff64b1ceb7
 	github.com/spf13/pflag v1.0.3
+	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2
+	golang.org/x/net v0.0.0-20190311183353-d8887717615a
+	golang.org/x/xerrors v0.0.0-20190717185122-a985d3407aa7 // indirect
+	gopkg.in/yaml.v2 v2.4.0
+	rsc.io/qr v0.2.0
 )
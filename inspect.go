@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// runInspect analyse base64 input and print diagnostics
+func runInspect(programName string, args []string) error {
+	flags := flag.NewFlagSet(programName+" inspect", flag.ContinueOnError)
+	help := flags.BoolP("help", "h", false, "print this help")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *help {
+		printCommandHelp(programName, "inspect", "Analyse base64 in FILE, or standard input, without decoding it to output.", flags)
+		return nil
+	}
+
+	file, err := getFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	report, err := xbase.Inspect64(file)
+	if err != nil {
+		return fmt.Errorf("inspect error: %v", err)
+	}
+	printReport(os.Stdout, report)
+	return nil
+}
+
+// printReport write human readable report to output
+func printReport(output io.Writer, report *xbase.Report) {
+	fmt.Fprintf(output, "Size:           %d bytes\n", report.Size)
+	fmt.Fprintf(output, "Alphabet:       %s\n", report.Alphabets())
+	fmt.Fprintf(output, "Padding:        %s\n", report.PaddingStyle())
+
+	lengths := make([]int, 0, len(report.LineLengths))
+	for length := range report.LineLengths {
+		lengths = append(lengths, length)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(lengths)))
+	histogram := make([]string, 0, len(lengths))
+	for _, length := range lengths {
+		histogram = append(histogram, fmt.Sprintf("%d x%d", length, report.LineLengths[length]))
+	}
+	lineEnding := ""
+	if report.CRLF > 0 {
+		lineEnding = fmt.Sprintf(", %d ending with CRLF", report.CRLF)
+	}
+	fmt.Fprintf(output, "Lines:          %d%s\n", report.Lines, lineEnding)
+	fmt.Fprintf(output, "Line lengths:   %s\n", strings.Join(histogram, ", "))
+	switch cols, regular := report.Wrap(); {
+	case !regular:
+		fmt.Fprintf(output, "Wrap:           irregular, longest line %d\n", cols)
+	default:
+		fmt.Fprintf(output, "Wrap:           %d (--wrap=%d)\n", cols, cols)
+	}
+
+	garbage := "none"
+	if report.Garbage > 0 {
+		positions := make([]string, len(report.GarbagePositions))
+		for i, position := range report.GarbagePositions {
+			positions[i] = fmt.Sprint(position)
+		}
+		more := ""
+		if report.Garbage > int64(len(positions)) {
+			more = ", ..."
+		}
+		garbage = fmt.Sprintf("%d characters at offsets %s%s (use --ignore-garbage)", report.Garbage, strings.Join(positions, ", "), more)
+	}
+	fmt.Fprintf(output, "Garbage:        %s\n", garbage)
+
+	trailing := "canonical"
+	switch {
+	case report.Truncated:
+		trailing = "truncated, a segment ends with a single character"
+	case report.NonCanonical:
+		trailing = "not canonical, unused bits are not zero"
+	}
+	fmt.Fprintf(output, "Trailing bits:  %s\n", trailing)
+	fmt.Fprintf(output, "Decoded size:   %d bytes\n", report.DecodedSize)
+	if report.DecodedSize > 0 {
+		fmt.Fprintf(output, "Content type:   %s\n", http.DetectContentType(report.Head))
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+func Test_printReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantOutput string
+	}{
+		{
+			"wrapped padded text",
+			"aGVsbG8g\nd29ybGQh\nCg==\n",
+			`Size:           23 bytes
+Alphabet:       standard or URL (no +/-_ characters)
+Padding:        padded (2)
+Lines:          3
+Line lengths:   8 x2, 4 x1
+Wrap:           8 (--wrap=8)
+Garbage:        none
+Trailing bits:  canonical
+Decoded size:   13 bytes
+Content type:   text/plain; charset=utf-8
+`,
+		},
+		{
+			"unpadded URL alphabet with garbage and CRLF",
+			"R0lG*ODlh_w\r\n",
+			`Size:           13 bytes
+Alphabet:       URL (-_)
+Padding:        unpadded (use --no-padding)
+Lines:          1, 1 ending with CRLF
+Line lengths:   11 x1
+Wrap:           0 (--wrap=0)
+Garbage:        1 characters at offsets 4 (use --ignore-garbage)
+Trailing bits:  canonical
+Decoded size:   7 bytes
+Content type:   image/gif
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := xbase.Inspect64(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Inspect64() error = %v", err)
+			}
+			output := &bytes.Buffer{}
+			printReport(output, report)
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("printReport() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
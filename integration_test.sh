@@ -21,3 +21,28 @@ for file in xbase/testdata/*.decode.std.*.std-garbage.padded.input; do
     diff <(/usr/bin/base64 -d -i "${file}") <(./build/base64 -d -i "${file}")
     diff <(/usr/bin/base64 --decode --ignore-garbage "${file}") <(./build/base64 --decode --ignore-garbage "${file}")
 done
+
+for file in xbase/testdata/*.decode.std.*.no-garbage.concatenated.input; do
+    echo "testing ${file}"
+    diff <(/usr/bin/base64 -d "${file}") <(./build/base64 -d --concatenated "${file}")
+done
+
+for file in xbase/testdata/*.decode.std.*.std-garbage.concatenated.input; do
+    echo "testing ${file}"
+    diff <(/usr/bin/base64 -d -i "${file}") <(./build/base64 -d -i --concatenated "${file}")
+done
+
+ln -sf base64 ./build/basenc
+for encoding in base64 base64url base32 base32hex base16 base2msbf base2lsbf z85; do
+    for file in xbase/testdata/*.encode.input; do
+        if [[ "${encoding}" == "z85" && $(( $(wc -c < "${file}") % 4 )) -ne 0 ]]; then
+            continue # z85 can encode only multiples of 4 bytes
+        fi
+        echo "testing basenc --${encoding} ${file}"
+        diff <(/usr/bin/basenc --"${encoding}" "${file}") <(./build/basenc --"${encoding}" "${file}")
+        diff <(/usr/bin/basenc --"${encoding}" --wrap=0 "${file}") <(./build/basenc --"${encoding}" --wrap=0 "${file}")
+        diff <(/usr/bin/basenc --"${encoding}" -w 137 "${file}") <(./build/base64 -c basenc --"${encoding}" -w 137 "${file}")
+        diff <(/usr/bin/basenc --"${encoding}" "${file}" | /usr/bin/basenc --"${encoding}" -d) <(/usr/bin/basenc --"${encoding}" "${file}" | ./build/basenc --"${encoding}" -d)
+        diff <(/usr/bin/basenc --"${encoding}" "${file}" | tr '\n' '~' | /usr/bin/basenc --"${encoding}" -d -i) <(/usr/bin/basenc --"${encoding}" "${file}" | tr '\n' '~' | ./build/basenc --"${encoding}" -d -i)
+    done
+done
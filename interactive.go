@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// repl encode or decode every line of input immediately
+type repl struct {
+	decode    bool
+	url       bool
+	noPadding bool
+	prompt    bool
+	history   []string
+
+	historyFile io.Writer
+	output      io.Writer
+	errOutput   io.Writer
+}
+
+// runInteractive run read-eval-print loop on standard input
+func runInteractive(programName string, args []string) error {
+	flags := flag.NewFlagSet(programName+" interactive", flag.ContinueOnError)
+	r := &repl{output: os.Stdout, errOutput: os.Stderr}
+	flags.BoolVarP(&r.decode, "decode", "d", false, "start in decoding mode")
+	flags.BoolVarP(&r.url, "url", "u", false, "start with URL alphabet")
+	flags.BoolVarP(&r.noPadding, "no-padding", "n", false, "start without padding of encoded lines")
+	historyFile := flags.String("history", "", "load history from `FILE` and append entered lines to it")
+	help := flags.BoolP("help", "h", false, "print this help")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *help {
+		printInteractiveHelp(programName, flags)
+		return nil
+	}
+
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q\nTry '%s interactive --help' for more information.", flags.Arg(0), programName)
+	}
+	if *historyFile != "" {
+		file, err := openHistory(r, *historyFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r.historyFile = file
+	}
+	r.prompt = isTerminal(os.Stdin)
+	return r.run(os.Stdin)
+}
+
+func printInteractiveHelp(programName string, flags *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: %s interactive [OPTION]...\n", programName)
+	fmt.Fprintf(os.Stderr, `
+Encode or decode every line typed on standard input immediately.
+
+`)
+	flags.PrintDefaults()
+	fmt.Fprint(os.Stderr, "\n"+replHelp)
+}
+
+const replHelp = `Commands:
+  :e         encode lines
+  :d         decode lines
+  :url       toggle URL alphabet
+  :raw       toggle padding of encoded lines
+  :history   print numbered history
+  !N         process line N of history again
+  :help      print this help
+  :q         quit (as end of input)
+Decoded binary data are printed as hexdump.
+`
+
+// openHistory load lines of fileName to history of r and return the file
+// opened for appending
+func openHistory(r *repl, fileName string) (*os.File, error) {
+	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open history: %v", err)
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		r.history = append(r.history, scanner.Text())
+	}
+	if err = scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("cannot read history: %v", err)
+	}
+	return file, nil
+}
+
+// run process lines of input until its end or :q, invalid lines are reported
+// and processing continues
+func (r *repl) run(input io.Reader) error {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for {
+		r.printPrompt()
+		if !scanner.Scan() {
+			break
+		}
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		switch line {
+		case ":q", ":quit":
+			return nil
+		case ":e":
+			r.decode = false
+		case ":d":
+			r.decode = true
+		case ":url":
+			r.url = !r.url
+		case ":raw":
+			r.noPadding = !r.noPadding
+		case ":history":
+			for i, h := range r.history {
+				fmt.Fprintf(r.output, "%5d  %s\n", i+1, h)
+			}
+		case ":help", ":h":
+			fmt.Fprint(r.output, replHelp)
+		default:
+			if err := r.processLine(line); err != nil {
+				fmt.Fprintf(r.errOutput, "error: %v\n", err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("cannot read input: %v", err)
+	}
+	return nil
+}
+
+func (r *repl) printPrompt() {
+	if !r.prompt {
+		return
+	}
+	mode := "enc"
+	if r.decode {
+		mode = "dec"
+	}
+	if r.url {
+		mode += " url"
+	}
+	if r.noPadding {
+		mode += " raw"
+	}
+	fmt.Fprintf(r.errOutput, "%s> ", mode)
+}
+
+// processLine encode or decode line, or line of history referenced by !N,
+// and add it to history
+func (r *repl) processLine(line string) error {
+	if strings.HasPrefix(line, ":") {
+		return fmt.Errorf("unknown command %q, try :help", line)
+	}
+	if strings.HasPrefix(line, "!") {
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 1 || n > len(r.history) {
+			return fmt.Errorf("no history line %q", line[1:])
+		}
+		line = r.history[n-1]
+		fmt.Fprintln(r.errOutput, line)
+	}
+	if line == "" {
+		return nil
+	}
+	r.addHistory(line)
+
+	result := &bytes.Buffer{}
+	if !r.decode {
+		if err := xbase.Encode64(strings.NewReader(line), result, getEncoding(r.noPadding, r.url), 0); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(r.output, result)
+		return err
+	}
+	// padding is optional and errors report offset within line
+	if err := xbase.Decode64Tracked(strings.NewReader(line), result, getEncoding(false, r.url), false); err != nil {
+		if oerr, ok := err.(*xbase.OffsetError); ok {
+			// point at the invalid character
+			return fmt.Errorf("%v at byte %d\n  %s\n  %s^", oerr.Err, oerr.Offset, line, strings.Repeat(" ", int(oerr.Offset)))
+		}
+		return err
+	}
+	if isBinary(result.Bytes()) {
+		hexdump := xbase.NewHexdumper(r.output)
+		if _, err := hexdump.Write(result.Bytes()); err != nil {
+			return err
+		}
+		return hexdump.Close()
+	}
+	_, err := fmt.Fprintln(r.output, result)
+	return err
+}
+
+func (r *repl) addHistory(line string) {
+	if n := len(r.history); n > 0 && r.history[n-1] == line {
+		return
+	}
+	r.history = append(r.history, line)
+	if r.historyFile != nil {
+		fmt.Fprintln(r.historyFile, line) // history is best effort
+	}
+}
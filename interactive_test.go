@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_repl_run(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantOutput string
+		wantErrors string
+	}{
+		{"encode", "hello\n\nhi\n", "aGVsbG8=\naGk=\n", ""},
+		{"decode with optional padding", ":d\naGVsbG8=\naGk\n", "hello\nhi\n", ""},
+		{"URL alphabet without padding", ":url\n:raw\n\xff\xfe\n:d\n__4\n", "__4\n00000000  ff fe                                             |..|\n00000002\n", ""},
+		{"binary as hexdump", ":d\nAAEC\n", "00000000  00 01 02                                          |...|\n00000003\n", ""},
+		{"invalid line", ":d\naGV*bG8=\naGk=\n", "hi\n", "error: illegal base64 data at byte 3\n  aGV*bG8=\n     ^\n"},
+		{"history", "a\nb\nb\n!1\n!9\n:history\n", "YQ==\nYg==\nYg==\nYQ==\n    1  a\n    2  b\n    3  a\n", "a\nerror: no history line \"9\"\n"},
+		{"quit", "a\n:q\nb\n", "YQ==\n", ""},
+		{"unknown command", ":x\n", "", "error: unknown command \":x\", try :help\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, errOutput := &bytes.Buffer{}, &bytes.Buffer{}
+			r := &repl{output: output, errOutput: errOutput}
+			if err := r.run(strings.NewReader(tt.input)); err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if output.String() != tt.wantOutput {
+				t.Errorf("run() output = %q, want %q", output, tt.wantOutput)
+			}
+			if errOutput.String() != tt.wantErrors {
+				t.Errorf("run() errors = %q, want %q", errOutput, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func Test_openHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "interactive")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+	fileName := filepath.Join(dir, "history")
+	if err = ioutil.WriteFile(fileName, []byte("aGk=\n"), 0600); err != nil {
+		t.Fatalf("cannot write history: %v", err)
+	}
+
+	r := &repl{decode: true, output: ioutil.Discard, errOutput: ioutil.Discard}
+	file, err := openHistory(r, fileName)
+	if err != nil {
+		t.Fatalf("openHistory() error = %v", err)
+	}
+	r.historyFile = file
+	err = r.run(strings.NewReader("!1\naGVsbG8=\n"))
+	file.Close()
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	got, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("cannot read history: %v", err)
+	}
+	if want := "aGk=\naGVsbG8=\n"; string(got) != want {
+		t.Errorf("history = %q, want %q", got, want)
+	}
+}
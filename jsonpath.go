@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// jsonObject keeps members of JSON object in the original order
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+
+// index return position of member with key or -1
+func (o jsonObject) index(key string) int {
+	for i := range o {
+		if o[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// get return value of member with key or nil
+func (o jsonObject) get(key string) interface{} {
+	if i := o.index(key); i >= 0 {
+		return o[i].value
+	}
+	return nil
+}
+
+func (o jsonObject) insert(i int, key string, value interface{}) jsonObject {
+	o = append(o, jsonMember{})
+	copy(o[i+1:], o[i:])
+	o[i] = jsonMember{key: key, value: value}
+	return o
+}
+
+func (o jsonObject) remove(key string) jsonObject {
+	if i := o.index(key); i >= 0 {
+		return append(o[:i], o[i+1:]...)
+	}
+	return o
+}
+
+// jsonPathStep is one step of a path expression, it selects an object member
+// by key, an array element by index or, for wildcard, all members or elements
+type jsonPathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+type jsonPath []jsonPathStep
+
+// parseJSONPath parse simple path expression like .data.*, .items[].value,
+// .items[0]."tls.crt" or . for the whole document
+func parseJSONPath(expr string) (path jsonPath, err error) {
+	rest := expr
+	if rest == "." {
+		return jsonPath{}, nil
+	}
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, `."`):
+			end := strings.IndexByte(rest[2:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unterminated quoted key", expr)
+			}
+			path = append(path, jsonPathStep{key: rest[2 : 2+end]})
+			rest = rest[3+end:]
+		case strings.HasPrefix(rest, "."):
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : 1+end]
+			switch key {
+			case "":
+				return nil, fmt.Errorf("invalid path %q: empty key", expr)
+			case "*":
+				path = append(path, jsonPathStep{wildcard: true})
+			default:
+				path = append(path, jsonPathStep{key: key})
+			}
+			rest = rest[1+end:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unterminated index", expr)
+			}
+			switch index := rest[1:end]; index {
+			case "", "*":
+				path = append(path, jsonPathStep{wildcard: true})
+			default:
+				i, err := strconv.Atoi(index)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("invalid path %q: bad index %q", expr, index)
+				}
+				path = append(path, jsonPathStep{index: i, isIndex: true})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q: expected . or [ at %q", expr, rest)
+		}
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("invalid path %q: path must start with .", expr)
+	}
+	return path, nil
+}
+
+// transform call fn for every string value selected by path and replace it
+// with returned value, values which do not exist are silently skipped
+func (p jsonPath) transform(value interface{}, location string, fn func(string) (string, error)) (interface{}, error) {
+	if len(p) == 0 {
+		if location == "" {
+			location = "."
+		}
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("value at %s is not a string", location)
+		}
+		s, err := fn(s)
+		if err != nil {
+			return nil, fmt.Errorf("value at %s: %v", location, err)
+		}
+		return s, nil
+	}
+
+	step, rest := p[0], p[1:]
+	var err error
+	switch v := value.(type) {
+	case jsonObject:
+		for i := range v {
+			if step.wildcard || (!step.isIndex && v[i].key == step.key) {
+				if v[i].value, err = rest.transform(v[i].value, location+"."+v[i].key, fn); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			if step.wildcard || (step.isIndex && i == step.index) {
+				if v[i], err = rest.transform(v[i], fmt.Sprintf("%s[%d]", location, i), fn); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return value, nil
+}
+
+// transformJSON read JSON documents (or NDJSON) from input, transform values
+// selected by path and write them to output preserving order of keys
+func transformJSON(input io.Reader, output io.Writer, path jsonPath, fn func(string) (string, error)) error {
+	return forEachJSONDocument(input, output, func(value interface{}) (interface{}, error) {
+		return path.transform(value, "", fn)
+	})
+}
+
+// forEachJSONDocument read JSON documents from input, replace each of them
+// with the result of transform and write it to output preserving order of
+// keys. Pretty printed documents are written indented, single line documents
+// on one line.
+func forEachJSONDocument(input io.Reader, output io.Writer, transform func(interface{}) (interface{}, error)) error {
+	decoder := json.NewDecoder(input)
+	for n := 1; ; n++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("cannot read JSON document %d: %v", n, err)
+		}
+
+		value, err := readJSON(raw)
+		if err != nil {
+			return fmt.Errorf("cannot read JSON document %d: %v", n, err)
+		}
+		if value, err = transform(value); err != nil {
+			return fmt.Errorf("JSON document %d: %v", n, err)
+		}
+
+		compact := &bytes.Buffer{}
+		if err = writeJSON(compact, value); err != nil {
+			return fmt.Errorf("cannot write JSON document %d: %v", n, err)
+		}
+		result := compact
+		if bytes.ContainsRune(raw, '\n') {
+			result = &bytes.Buffer{}
+			if err = json.Indent(result, compact.Bytes(), "", "  "); err != nil {
+				return fmt.Errorf("cannot indent JSON document %d: %v", n, err)
+			}
+		}
+		result.WriteByte('\n')
+		if _, err = output.Write(result.Bytes()); err != nil {
+			return fmt.Errorf("cannot write to output: %v", err)
+		}
+	}
+}
+
+// readJSON parse one JSON document into jsonObject, []interface{}, string,
+// json.Number, bool or nil values
+func readJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return readJSONValue(decoder)
+}
+
+func readJSONValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := jsonObject{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, jsonMember{key: key.(string), value: value})
+		}
+		_, err = decoder.Token() // closing }
+		return object, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := readJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = decoder.Token() // closing ]
+		return array, err
+	}
+	return token, nil
+}
+
+// writeJSON write value read by readJSON to output in compact form
+func writeJSON(output *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case jsonObject:
+		output.WriteByte('{')
+		for i, member := range v {
+			if i > 0 {
+				output.WriteByte(',')
+			}
+			if err := writeJSON(output, member.key); err != nil {
+				return err
+			}
+			output.WriteByte(':')
+			if err := writeJSON(output, member.value); err != nil {
+				return err
+			}
+		}
+		output.WriteByte('}')
+	case []interface{}:
+		output.WriteByte('[')
+		for i, element := range v {
+			if i > 0 {
+				output.WriteByte(',')
+			}
+			if err := writeJSON(output, element); err != nil {
+				return err
+			}
+		}
+		output.WriteByte(']')
+	case string:
+		if !utf8.ValidString(v) {
+			return fmt.Errorf("string is not valid UTF-8")
+		}
+		encoder := json.NewEncoder(output)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		output.Truncate(output.Len() - 1) // drop newline added by Encode
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		output.Write(b)
+	}
+	return nil
+}
+
+// jsonCodec return function which encode or decode single JSON string value
+func jsonCodec(decode bool, encoding *base64.Encoding, ignoreGarbage bool) func(string) (string, error) {
+	return func(value string) (string, error) {
+		output := &bytes.Buffer{}
+		if !decode {
+			if err := xbase.Encode64(strings.NewReader(value), output, encoding, 0); err != nil {
+				return "", err
+			}
+			return output.String(), nil
+		}
+		if err := xbase.Decode64(strings.NewReader(value), output, encoding, ignoreGarbage); err != nil {
+			return "", err
+		}
+		if !utf8.Valid(output.Bytes()) {
+			return "", fmt.Errorf("decoded value is not valid UTF-8")
+		}
+		return output.String(), nil
+	}
+}
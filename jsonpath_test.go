@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseJSONPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		wantPath jsonPath
+		wantErr  bool
+	}{
+		{"whole document", ".", jsonPath{}, false},
+		{"single key", ".data", jsonPath{{key: "data"}}, false},
+		{"wildcard member", ".data.*", jsonPath{{key: "data"}, {wildcard: true}}, false},
+		{"quoted key with dot", `.data."tls.crt"`, jsonPath{{key: "data"}, {key: "tls.crt"}}, false},
+		{"array wildcard", ".items[].value", jsonPath{{key: "items"}, {wildcard: true}, {key: "value"}}, false},
+		{"array star", ".items[*]", jsonPath{{key: "items"}, {wildcard: true}}, false},
+		{"array index", ".items[2].value", jsonPath{{key: "items"}, {index: 2, isIndex: true}, {key: "value"}}, false},
+		{"empty expression", "", nil, true},
+		{"missing leading dot", "data", nil, true},
+		{"empty key", ".data..value", nil, true},
+		{"unterminated quoted key", `."data`, nil, true},
+		{"unterminated index", ".items[1", nil, true},
+		{"negative index", ".items[-1]", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPath, err := parseJSONPath(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseJSONPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotPath, tt.wantPath) {
+				t.Errorf("parseJSONPath() = %+v, want %+v", gotPath, tt.wantPath)
+			}
+		})
+	}
+}
+
+func Test_transformJSON(t *testing.T) {
+	type args struct {
+		input  string
+		path   string
+		decode bool
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantOutput string
+		wantErr    bool
+	}{
+		{
+			"decode Secret data keeping key order",
+			args{`{"kind":"Secret","data":{"z":"aGVsbG8=","a":"PHRhZz4="},"n":1.50}`, ".data.*", true},
+			`{"kind":"Secret","data":{"z":"hello","a":"<tag>"},"n":1.50}` + "\n",
+			false,
+		},
+		{
+			"encode single value in NDJSON",
+			args{"{\"a\":\"x\",\"b\":\"y\"}\n{\"b\":\"z\"}\n{}\n", ".b", false},
+			"{\"a\":\"x\",\"b\":\"eQ==\"}\n{\"b\":\"eg==\"}\n{}\n",
+			false,
+		},
+		{
+			"pretty printed document stays pretty printed",
+			args{"{\n  \"data\": {\"b\": \"hi\"}\n}", ".data.b", false},
+			"{\n  \"data\": {\n    \"b\": \"aGk=\"\n  }\n}\n",
+			false,
+		},
+		{
+			"array elements",
+			args{`{"items":[{"v":"YQ=="},{"v":"Yg=="},{"w":"Yw=="}]}`, ".items[].v", true},
+			`{"items":[{"v":"a"},{"v":"b"},{"w":"Yw=="}]}` + "\n",
+			false,
+		},
+		{
+			"whole document string",
+			args{`"YQ=="`, ".", true},
+			"\"a\"\n",
+			false,
+		},
+		{"selected value is not a string", args{`{"a":1}`, ".a", false}, "", true},
+		{"selected value is not valid base64", args{`{"a":"*"}`, ".a", true}, "", true},
+		{"decoded value is not valid UTF-8", args{`{"a":"/w=="}`, ".a", true}, "", true},
+		{"invalid JSON", args{`{"a":`, ".a", true}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := parseJSONPath(tt.args.path)
+			if err != nil {
+				t.Fatalf("parseJSONPath(%q) error = %v", tt.args.path, err)
+			}
+			output := &bytes.Buffer{}
+			codec := jsonCodec(tt.args.decode, base64.StdEncoding, false)
+			if err = transformJSON(strings.NewReader(tt.args.input), output, path, codec); (err != nil) != tt.wantErr {
+				t.Errorf("transformJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("transformJSON() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
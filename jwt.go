@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// register hash functions used by JWS algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// runJWT print decoded header and payload of compact JWS and optionally
+// verify its signature
+func runJWT(programName string, args []string) error {
+	flags := flag.NewFlagSet(programName+" jwt", flag.ContinueOnError)
+	var (
+		keyFile = flags.StringP("key", "k", "", "verify signature with HMAC secret or PEM public key\nor certificate in KEYFILE")
+		alg     = flags.String("alg", "", "with --key, expected signature algorithm `ALG`, token\nsigned by other algorithm is rejected")
+		help    = flags.BoolP("help", "h", false, "print this help")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *help {
+		printJWTHelp(programName, flags)
+		return nil
+	}
+
+	file, err := getFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	input, err := ioutil.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("cannot read from input: %v", err)
+	}
+	token := strings.TrimSpace(string(input))
+
+	if err = inspectJWT(token, os.Stdout, time.Now()); err != nil {
+		return fmt.Errorf("jwt error: %v", err)
+	}
+
+	if *keyFile == "" {
+		if *alg != "" {
+			return fmt.Errorf("--alg can be used only with --key")
+		}
+		return nil
+	}
+	if *alg == "" {
+		return fmt.Errorf("--key needs --alg with expected signature algorithm")
+	}
+	key, err := ioutil.ReadFile(filepath.Clean(*keyFile))
+	if err != nil {
+		return fmt.Errorf("cannot read key %s: %v", *keyFile, err)
+	}
+	if _, err = verifyJWT(token, key, *alg); err != nil {
+		return fmt.Errorf("signature verification failed: %v", err)
+	}
+	fmt.Fprintf(os.Stdout, "Signature: verified (%s)\n", *alg)
+	return nil
+}
+
+func printJWTHelp(programName string, flags *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: %s jwt [OPTION]... [FILE]\n", programName)
+	fmt.Fprintf(os.Stderr, `
+Decode header and payload of compact JWS (JWT) from FILE, or standard input,
+and print them to standard output. The signature is not verified unless
+--key is given.
+With no FILE, or when FILE is -, read standard input.
+
+`)
+	flags.PrintDefaults()
+	fmt.Fprintf(os.Stderr, `
+Supported algorithms for verification are HS256, HS384, HS512, RS256, RS384,
+RS512, ES256, ES384 and ES512. The algorithm must be given by --alg, the one
+in token header is not trusted. HMAC secret is used as is, including any
+trailing newline in KEYFILE, PEM keys and certificates are refused as HMAC
+secrets.
+`)
+}
+
+// splitJWT split compact JWS into decoded header, payload and signature
+func splitJWT(token string) (header, payload, signature []byte, err error) {
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return nil, nil, nil, fmt.Errorf("token must have 3 segments separated by '.', got %d", len(segments))
+	}
+
+	encoding := getEncoding(true, true)
+	decoded := make([][]byte, len(segments))
+	for i, name := range []string{"header", "payload", "signature"} {
+		buffer := &bytes.Buffer{}
+		if err = xbase.Decode64(strings.NewReader(segments[i]), buffer, encoding, false); err != nil {
+			return nil, nil, nil, fmt.Errorf("cannot decode %s: %v", name, err)
+		}
+		decoded[i] = buffer.Bytes()
+	}
+	return decoded[0], decoded[1], decoded[2], nil
+}
+
+// inspectJWT write pretty printed header and payload of token to output
+// together with registered time claims and length of signature
+func inspectJWT(token string, output io.Writer, now time.Time) error {
+	header, payload, signature, err := splitJWT(token)
+	if err != nil {
+		return err
+	}
+
+	for _, part := range []struct {
+		name string
+		data []byte
+	}{{"Header", header}, {"Payload", payload}} {
+		pretty := &bytes.Buffer{}
+		if err = json.Indent(pretty, part.data, "", "  "); err != nil {
+			return fmt.Errorf("%s is not valid JSON: %v", strings.ToLower(part.name), err)
+		}
+		fmt.Fprintf(output, "%s:\n%s\n", part.name, pretty)
+	}
+
+	var claims map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err = decoder.Decode(&claims); err == nil {
+		var printed bool
+		for _, claim := range []string{"iat", "nbf", "exp"} {
+			number, ok := claims[claim].(json.Number)
+			if !ok {
+				continue
+			}
+			seconds, err := number.Float64()
+			if err != nil {
+				continue
+			}
+			if !printed {
+				fmt.Fprintf(output, "Claims:\n")
+				printed = true
+			}
+			at := time.Unix(int64(seconds), 0).UTC()
+			var note string
+			switch {
+			case claim == "exp" && !now.Before(at):
+				note = " (expired)"
+			case claim == "nbf" && now.Before(at):
+				note = " (not yet valid)"
+			}
+			fmt.Fprintf(output, "  %s: %s%s\n", claim, at.Format(time.RFC3339), note)
+		}
+	}
+
+	fmt.Fprintf(output, "Signature: %d bytes\n", len(signature))
+	return nil
+}
+
+// verifyJWT verify signature of token with HMAC secret or PEM encoded public
+// key and return used algorithm, which must be expected one
+func verifyJWT(token string, key []byte, expected string) (alg string, err error) {
+	header, _, signature, err := splitJWT(token)
+	if err != nil {
+		return "", err
+	}
+	var h struct {
+		Alg string `json:"alg"`
+	}
+	if err = json.Unmarshal(header, &h); err != nil {
+		return "", fmt.Errorf("header is not valid JSON: %v", err)
+	}
+	alg = h.Alg
+	if alg != expected {
+		return alg, fmt.Errorf("token is signed by %q, expected %s", alg, expected)
+	}
+
+	var hash crypto.Hash
+	switch {
+	case len(alg) == 5 && strings.HasSuffix(alg, "256"):
+		hash = crypto.SHA256
+	case len(alg) == 5 && strings.HasSuffix(alg, "384"):
+		hash = crypto.SHA384
+	case len(alg) == 5 && strings.HasSuffix(alg, "512"):
+		hash = crypto.SHA512
+	default:
+		return alg, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	signingInput := []byte(token[:strings.LastIndexByte(token, '.')])
+
+	if strings.HasPrefix(alg, "HS") {
+		if block, _ := pem.Decode(key); block != nil {
+			// public key is known to everybody, see algorithm confusion
+			return alg, fmt.Errorf("PEM %s cannot be HMAC secret", strings.ToLower(block.Type))
+		}
+		mac := hmac.New(hash.New, key)
+		mac.Write(signingInput)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return alg, fmt.Errorf("%s signature does not match", alg)
+		}
+		return alg, nil
+	}
+
+	publicKey, err := parsePublicKey(key)
+	if err != nil {
+		return alg, err
+	}
+	digest := hash.New()
+	digest.Write(signingInput)
+	hashed := digest.Sum(nil)
+
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return alg, fmt.Errorf("RSA key cannot verify %s signature", alg)
+		}
+		if err = rsa.VerifyPKCS1v15(pub, hash, hashed, signature); err != nil {
+			return alg, fmt.Errorf("%s signature does not match", alg)
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return alg, fmt.Errorf("ECDSA key cannot verify %s signature", alg)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return alg, fmt.Errorf("%s signature must be %d bytes, got %d", alg, 2*size, len(signature))
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, hashed, r, s) {
+			return alg, fmt.Errorf("%s signature does not match", alg)
+		}
+	default:
+		return alg, fmt.Errorf("unsupported key type %T", publicKey)
+	}
+	return alg, nil
+}
+
+// parsePublicKey parse PEM encoded PKIX or PKCS #1 public key or certificate
+func parsePublicKey(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key is not PEM encoded")
+	}
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return certificate.PublicKey, nil
+	}
+	return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// maxAccidentalNewline is the longest standard input whose single trailing
+// newline is reported as likely accidental (e.g. echo instead of echo -n)
+const maxAccidentalNewline = 1024
+
+// runCodecStrings encode or decode every string of --string independently
+// to the same destination
+func runCodecStrings(opts *codecOptions, fileName string) (err error) {
+	if fileName != "" || opts.fromClipboard || opts.archive != "" {
+		return fmt.Errorf("--string cannot be used with FILE, --from-clipboard or --archive")
+	}
+	if opts.toClipboard && len(opts.strings) > 1 {
+		return fmt.Errorf("--to-clipboard can be used only with one --string")
+	}
+	destination, flush, err := openDestination(opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if ferr := flush(); err == nil && ferr != nil {
+			err = ferr
+		}
+	}()
+	newline := opts.newline
+	if newline == "auto" && opts.decode && (len(opts.strings) > 1 || isTerminalOutput(destination)) {
+		newline = "always" // separate decoded strings and keep prompt on its own line
+	}
+	for i := range opts.strings {
+		literal := opts.strings[i]
+		stringOpts := *opts
+		stringOpts.strings = nil
+		stringOpts.literal = &literal
+		stringOpts.newline = newline
+		stringOpts.destination = destination
+		if err := runCodec(&stringOpts, ""); err != nil {
+			return fmt.Errorf("string %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// newlineWriter apply --newline policy to the end of output, with always
+// newline is added when missing, with never trailing newline is removed
+type newlineWriter struct {
+	policy  string
+	last    byte
+	written bool
+	held    bool // trailing newline held back with never policy
+
+	w io.Writer
+}
+
+func newNewlineWriter(w io.Writer, policy string) (*newlineWriter, error) {
+	switch policy {
+	case "", "auto", "always", "never":
+		return &newlineWriter{policy: policy, w: w}, nil
+	}
+	return nil, fmt.Errorf("invalid newline policy %q, use auto, always or never", policy)
+}
+
+func (nw *newlineWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n := len(p)
+	if nw.policy == "never" {
+		if nw.held {
+			if _, err := nw.w.Write([]byte{'\n'}); err != nil {
+				return 0, err
+			}
+			nw.held = false
+		}
+		if p[len(p)-1] == '\n' {
+			p, nw.held = p[:len(p)-1], true
+		}
+	}
+	if _, err := nw.w.Write(p); err != nil {
+		return 0, err
+	}
+	if len(p) > 0 {
+		nw.written, nw.last = true, p[len(p)-1]
+	}
+	return n, nil
+}
+
+func (nw *newlineWriter) Close() error {
+	if nw.policy == "always" && (!nw.written || nw.last != '\n') {
+		// also empty output gets a newline, so every string has its line
+		_, err := nw.w.Write([]byte{'\n'})
+		return err
+	}
+	return nil
+}
+
+// newlineSniffer remember whether input read through it is a single line
+// ending with newline
+type newlineSniffer struct {
+	size     int64
+	newlines int64
+	last     byte
+
+	r io.Reader
+}
+
+func (ns *newlineSniffer) Read(p []byte) (int, error) {
+	n, err := ns.r.Read(p)
+	if n > 0 {
+		ns.size += int64(n)
+		ns.newlines += int64(bytes.Count(p[:n], []byte{'\n'}))
+		ns.last = p[n-1]
+	}
+	return n, err
+}
+
+// accidentalNewline report whether input looks like echo output whose
+// trailing newline was not meant to be encoded
+func (ns *newlineSniffer) accidentalNewline() bool {
+	return ns.newlines == 1 && ns.last == '\n' && ns.size <= maxAccidentalNewline
+}
+
+// isTerminalOutput report whether w writes to terminal
+func isTerminalOutput(w io.Writer) bool {
+	if nw, ok := w.(*newlineWriter); ok {
+		w = nw.w
+	}
+	file, ok := w.(*os.File)
+	return ok && isTerminal(file)
+}
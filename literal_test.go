@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_newlineWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		writes []string
+		want   string
+	}{
+		{"auto keeps output", "auto", []string{"hi"}, "hi"},
+		{"always adds newline", "always", []string{"hi"}, "hi\n"},
+		{"always keeps newline", "always", []string{"hi\n"}, "hi\n"},
+		{"always on empty output", "always", nil, "\n"},
+		{"never removes newline", "never", []string{"aGk=\n"}, "aGk="},
+		{"never keeps inner newlines", "never", []string{"a\n", "b\n"}, "a\nb"},
+		{"never keeps other output", "never", []string{"hi"}, "hi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			nw, err := newNewlineWriter(output, tt.policy)
+			if err != nil {
+				t.Fatalf("newNewlineWriter() error = %v", err)
+			}
+			for _, w := range tt.writes {
+				if n, err := nw.Write([]byte(w)); err != nil || n != len(w) {
+					t.Fatalf("Write(%q) = %d, %v", w, n, err)
+				}
+			}
+			if err = nw.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if output.String() != tt.want {
+				t.Errorf("output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+
+	if _, err := newNewlineWriter(nil, "sometimes"); err == nil {
+		t.Errorf("newNewlineWriter(%q) error = nil", "sometimes")
+	}
+}
+
+func Test_newlineSniffer(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"echo output", "secret\n", true},
+		{"without newline", "secret", false},
+		{"more lines", "a\nb\n", false},
+		{"newline inside", "a\nb", false},
+		{"empty", "", false},
+		{"long line", strings.Repeat("a", maxAccidentalNewline) + "\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sniffer := &newlineSniffer{r: strings.NewReader(tt.input)}
+			if _, err := (&bytes.Buffer{}).ReadFrom(sniffer); err != nil {
+				t.Fatalf("ReadFrom() error = %v", err)
+			}
+			if got := sniffer.accidentalNewline(); got != tt.want {
+				t.Errorf("accidentalNewline() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runCodecStrings(t *testing.T) {
+	fake := &fakeClipboard{}
+	defer func(original func() (clipboard, error)) { newClipboard = original }(newClipboard)
+	newClipboard = func() (clipboard, error) { return fake, nil }
+
+	tests := []struct {
+		name string
+		opts codecOptions
+		want string
+	}{
+		{"encode", codecOptions{strings: []string{"hello"}, wrapAfter: 76}, "aGVsbG8=\n"},
+		{"encode without newline", codecOptions{strings: []string{"hello"}, wrapAfter: 76, newline: "never"}, "aGVsbG8="},
+		{"decode", codecOptions{strings: []string{"aGVsbG8="}, decode: true}, "hello"},
+		{"decode with newline", codecOptions{strings: []string{"aGVsbG8="}, decode: true, newline: "always"}, "hello\n"},
+		{"transcode", codecOptions{strings: []string{"aGk="}, to: "base16"}, "6869"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.toClipboard = true
+			if err := runCodec(&opts, ""); err != nil {
+				t.Fatalf("runCodec() error = %v", err)
+			}
+			if got := string(fake.content); got != tt.want {
+				t.Errorf("clipboard = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, opts := range []codecOptions{
+		{strings: []string{"a", "b"}, toClipboard: true},
+		{strings: []string{"a"}, fromClipboard: true},
+		{strings: []string{"not base64!"}, decode: true, toClipboard: true},
+	} {
+		if err := runCodec(&opts, ""); err == nil {
+			t.Errorf("runCodec(%+v) error = nil", opts)
+		}
+	}
+	if err := runCodec(&codecOptions{strings: []string{"a"}}, "file"); err == nil {
+		t.Errorf("runCodec() with --string and FILE error = nil")
+	}
+}
+
+func Test_runCodecStringsNewlineOnTerminal(t *testing.T) {
+	fake := &fakeClipboard{}
+	defer func(original func() (clipboard, error)) { newClipboard = original }(newClipboard)
+	newClipboard = func() (clipboard, error) { return fake, nil }
+
+	dir, err := ioutil.TempDir("", "literal")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+	output := filepath.Join(dir, "output")
+
+	// standard output is a fake terminal, other files are not
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatalf("cannot create fake standard output: %v", err)
+	}
+	defer stdout.Close()
+	defer func(original *os.File) { os.Stdout = original }(os.Stdout)
+	os.Stdout = stdout
+	defer func(original func(*os.File) bool) { isTerminal = original }(isTerminal)
+	isTerminal = func(file *os.File) bool { return file == stdout }
+
+	tests := []struct {
+		name string
+		opts codecOptions
+		file string
+		want string
+	}{
+		{"terminal", codecOptions{}, stdout.Name(), "hello\n"},
+		{"output file", codecOptions{output: output}, output, "hello"},
+		{"more strings to output file", codecOptions{output: output, strings: []string{"aGVsbG8=", "d29ybGQ="}}, output, "hello\nworld\n"},
+		{"clipboard", codecOptions{toClipboard: true}, "", "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.decode, opts.newline = true, "auto"
+			if opts.strings == nil {
+				opts.strings = []string{"aGVsbG8="}
+			}
+			if err := runCodec(&opts, ""); err != nil {
+				t.Fatalf("runCodec() error = %v", err)
+			}
+			got := string(fake.content)
+			if tt.file != "" {
+				content, err := ioutil.ReadFile(tt.file)
+				if err != nil {
+					t.Fatalf("cannot read output: %v", err)
+				}
+				got = string(content)
+			}
+			if got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
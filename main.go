@@ -9,8 +9,6 @@ import (
 	"text/tabwriter"
 
 	flag "github.com/spf13/pflag"
-
-	"github.com/zemanlx/base64/xbase"
 )
 
 var (
@@ -24,57 +22,56 @@ func main() {
 	defer func() {
 		if returnErr != nil {
 			fmt.Fprint(os.Stderr, returnErr)
+			if e, ok := returnErr.(interface{ ExitCode() int }); ok {
+				os.Exit(e.ExitCode())
+			}
 			os.Exit(1)
 		}
 	}()
 
-	var (
-		decode        = flag.BoolP("decode", "d", false, "decode data")
-		ignoreGarbage = flag.BoolP("ignore-garbage", "i", false, "when decoding, ignore non-alphabet characters")
-		noPadding     = flag.BoolP("no-padding", "n", false, "omit padding")
-		url           = flag.BoolP("url", "u", false, "use URL encoding according RFC4648")
-		wrapAfter     = flag.UintP("wrap", "w", 76, "wrap encoded lines after COLS character,\nuse 0 to disable line wrapping")
-		showVersion   = flag.BoolP("version", "v", false, "output version information and exit")
-		help          = flag.BoolP("help", "h", false, "print this help")
-	)
-	flag.Parse()
+	programName := filepath.Base(os.Args[0])
 
-	if *help {
-		printHelp(filepath.Base(os.Args[0]))
+	if programName == "basenc" {
+		returnErr = runBasenc(programName, os.Args[1:])
 		return
 	}
 
-	if *showVersion {
-		printVersion(os.Stdout)
+	cmd, args, ok, err := parseCommand(os.Args[1:])
+	if err != nil {
+		returnErr = err
+		return
+	}
+	if ok {
+		returnErr = cmd.run(programName+" -c", args)
 		return
 	}
 
-	encoding := getEncoding(*noPadding, *url)
+	var (
+		decode      = flag.BoolP("decode", "d", false, "decode data")
+		showVersion = flag.BoolP("version", "v", false, "output version information and exit")
+		help        = flag.BoolP("help", "h", false, "print this help")
+	)
+	opts := registerCodecFlags(flag.CommandLine, true, true)
+	flag.Parse()
 
-	file, err := getFile(flag.Arg(0))
-	if err != nil {
-		returnErr = err
+	if *help {
+		printHelp(programName)
 		return
 	}
-	defer file.Close()
 
-	if !*decode { // encode
-		if err = xbase.Encode64(file, os.Stdout, encoding, *wrapAfter); err != nil {
-			returnErr = fmt.Errorf("encode pipeline error: %v", err)
-			return
-		}
+	if *showVersion {
+		printVersion(os.Stdout)
+		return
 	}
 
-	if *decode {
-		if err = xbase.Decode64(file, os.Stdout, encoding, *ignoreGarbage); err != nil {
-			returnErr = fmt.Errorf("encode pipeline error: %v", err)
-			return
-		}
-	}
+	opts.decode = *decode
+	opts.paths = flag.Args()
+	returnErr = runCodec(opts, flag.Arg(0))
 }
 
 func printHelp(programName string) {
 	fmt.Fprintf(os.Stderr, "Usage: %s [OPTION]... [FILE]\n", programName)
+	fmt.Fprintf(os.Stderr, "  or:  %s -c COMMAND [OPTION]... [ARG]...\n", programName)
 	fmt.Fprintf(os.Stderr, `
 Base64 encode or decode FILE, or standard input, to standard output.
 With no FILE, or when FILE is -, read standard input.
@@ -87,6 +84,7 @@ When decoding, the input may contain newlines in addition to the bytes of
 the formal base64 alphabet.  Use --ignore-garbage to attempt to recover
 from any other non-alphabet bytes in the encoded stream.
 `)
+	printCommands(programName)
 }
 
 func printVersion(dst io.Writer) {
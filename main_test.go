@@ -98,6 +98,7 @@ func Test_printHelp(t *testing.T) {
 			"print help for program hulahop",
 			args{"hulahop"},
 			`Usage: hulahop [OPTION]... [FILE]
+  or:  hulahop -c COMMAND [OPTION]... [ARG]...
 
 Base64 encode or decode FILE, or standard input, to standard output.
 With no FILE, or when FILE is -, read standard input.
@@ -107,6 +108,19 @@ The data are encoded as described for the base64 alphabet in RFC 4648.
 When decoding, the input may contain newlines in addition to the bytes of
 the formal base64 alphabet.  Use --ignore-garbage to attempt to recover
 from any other non-alphabet bytes in the encoded stream.
+
+Commands:
+  enc          encode data
+  dec          decode data
+  basenc       encode or decode data as GNU basenc
+  inspect      analyse base64 input and print diagnostics
+  jwt          inspect JWS (JWT) and optionally verify its signature
+  secret       decode or encode data of Kubernetes Secret manifests
+  sri          print or verify Subresource Integrity hashes
+  rand         generate random tokens
+  interactive  encode or decode typed lines immediately
+
+Run 'hulahop -c COMMAND --help' for more information on a command.
 `,
 		},
 	}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"rsc.io/qr"
+)
+
+// defaultQRPartSize keeps codes of split payload at QR version about 20 with
+// medium error correction, which is still easy to scan from terminal
+const defaultQRPartSize = 600
+
+// qrQuietZone is width of light border around code in modules as required
+// by ISO/IEC 18004
+const qrQuietZone = 4
+
+// qrWriter collect encoded data and write them as QR codes on Close, data
+// longer than size are split to parts with headers, one part per code
+type qrWriter struct {
+	buffer  bytes.Buffer
+	size    uint
+	pngName string // write PNG files instead of text to w
+
+	w io.Writer
+}
+
+func (q *qrWriter) Write(p []byte) (int, error) {
+	return q.buffer.Write(p)
+}
+
+func (q *qrWriter) Close() error {
+	payloads, err := qrPayloads(q.buffer.Bytes(), q.size)
+	if err != nil {
+		return err
+	}
+	for i, payload := range payloads {
+		code, err := qr.Encode(payload, qr.M)
+		if err != nil {
+			return fmt.Errorf("cannot create QR code %d of %d bytes: %v", i+1, len(payload), err)
+		}
+		if q.pngName != "" {
+			name := qrFileName(q.pngName, i+1, len(payloads))
+			if err = ioutil.WriteFile(name, code.PNG(), 0644); err != nil {
+				return fmt.Errorf("cannot write QR code: %v", err)
+			}
+			continue
+		}
+		if i > 0 {
+			if _, err = fmt.Fprintln(q.w); err != nil {
+				return fmt.Errorf("cannot write to output: %v", err)
+			}
+		}
+		if len(payloads) > 1 {
+			if _, err = fmt.Fprintf(q.w, "QR %d/%d\n", i+1, len(payloads)); err != nil {
+				return fmt.Errorf("cannot write to output: %v", err)
+			}
+		}
+		if err = writeQRText(q.w, code); err != nil {
+			return fmt.Errorf("cannot write to output: %v", err)
+		}
+	}
+	return nil
+}
+
+// qrPayloads return encoded data as one payload when they fit to size,
+// otherwise as parts like --split-size, which can be joined by --join
+func qrPayloads(encoded []byte, size uint) ([]string, error) {
+	if uint(len(encoded)) <= size {
+		return []string{string(encoded)}, nil
+	}
+	return splitParts(encoded, size)
+}
+
+// qrFileName return name of i-th of n PNG files, name is used as it is for
+// the only file, otherwise the number is added before extension
+func qrFileName(name string, i, n int) string {
+	if n == 1 {
+		return name
+	}
+	ext := filepath.Ext(name)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext)
+}
+
+// writeQRText draw code with quiet zone by Unicode half blocks, two modules
+// per character, light modules are drawn for terminals with dark background
+func writeQRText(w io.Writer, code *qr.Code) error {
+	end := code.Size + qrQuietZone
+	light := func(x, y int) bool {
+		return y < end && !code.Black(x, y)
+	}
+	b := &strings.Builder{}
+	for y := -qrQuietZone; y < end; y += 2 {
+		for x := -qrQuietZone; x < end; x++ {
+			switch top, bottom := light(x, y), light(x, y+1); {
+			case top && bottom:
+				b.WriteRune('█')
+			case top:
+				b.WriteRune('▀')
+			case bottom:
+				b.WriteRune('▄')
+			default:
+				b.WriteRune(' ')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
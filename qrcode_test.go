@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"rsc.io/qr"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// decodeQR read QR code in img by independent decoder
+func decodeQR(t *testing.T, img image.Image) string {
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		t.Fatalf("NewBinaryBitmapFromImage() error = %v", err)
+	}
+	result, err := qrcode.NewQRCodeReader().Decode(bitmap, nil)
+	if err != nil {
+		t.Fatalf("cannot decode QR code: %v", err)
+	}
+	return result.GetText()
+}
+
+// textImage convert QR code drawn by writeQRText to image, 4 pixels per module
+func textImage(text string) image.Image {
+	const scale = 4
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	width := len([]rune(lines[0]))
+	img := image.NewGray(image.Rect(0, 0, width*scale, len(lines)*2*scale))
+	for y, line := range lines {
+		for x, r := range []rune(line) {
+			top, bottom := r == '█' || r == '▀', r == '█' || r == '▄'
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					if top {
+						img.SetGray(x*scale+dx, 2*y*scale+dy, color.Gray{0xff})
+					}
+					if bottom {
+						img.SetGray(x*scale+dx, (2*y+1)*scale+dy, color.Gray{0xff})
+					}
+				}
+			}
+		}
+	}
+	return img
+}
+
+func Test_writeQRText(t *testing.T) {
+	for _, payload := range []string{"aGVsbG8=\n", strings.Repeat("QUJD", 150) + "\n"} {
+		code, err := qr.Encode(payload, qr.M)
+		if err != nil {
+			t.Fatalf("qr.Encode() error = %v", err)
+		}
+		output := &bytes.Buffer{}
+		if err = writeQRText(output, code); err != nil {
+			t.Fatalf("writeQRText() error = %v", err)
+		}
+		if got := decodeQR(t, textImage(output.String())); got != payload {
+			t.Errorf("decoded QR code = %q, want %q", got, payload)
+		}
+	}
+}
+
+func Test_qrWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qrcode")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	data := make([]byte, 1000)
+	rand.New(rand.NewSource(1)).Read(data)
+	codes := &qrWriter{size: defaultQRPartSize, pngName: filepath.Join(dir, "key.png")}
+	if err = xbase.Encode64(bytes.NewReader(data), codes, base64.StdEncoding, 76); err != nil {
+		t.Fatalf("Encode64() error = %v", err)
+	}
+	if err = codes.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// scan codes in reverse order like a careless user
+	var scanned []string
+	for i := 3; i >= 1; i-- {
+		file, err := os.Open(qrFileName(filepath.Join(dir, "key.png"), i, 3))
+		if err != nil {
+			t.Fatalf("cannot open QR code: %v", err)
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("cannot decode PNG: %v", err)
+		}
+		scanned = append(scanned, decodeQR(t, img))
+	}
+	joined, err := joinParts(strings.NewReader(strings.Join(scanned, "\n")))
+	if err != nil {
+		t.Fatalf("joinParts() error = %v", err)
+	}
+	decoded := &bytes.Buffer{}
+	if err = xbase.Decode64(bytes.NewReader(joined), decoded, base64.StdEncoding, false); err != nil {
+		t.Fatalf("Decode64() error = %v", err)
+	}
+	if !bytes.Equal(decoded.Bytes(), data) {
+		t.Errorf("data of QR codes differ from original data")
+	}
+
+	output := &bytes.Buffer{}
+	single := &qrWriter{size: defaultQRPartSize, w: output}
+	single.Write([]byte("aGVsbG8=\n"))
+	if err = single.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := decodeQR(t, textImage(output.String())); got != "aGVsbG8=\n" {
+		t.Errorf("decoded QR code = %q, want %q", got, "aGVsbG8=\n")
+	}
+	if strings.Contains(output.String(), "QR 1/1") {
+		t.Errorf("single QR code must not have caption")
+	}
+}
+
+func Test_qrFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		i, n int
+		want string
+	}{
+		{"key.png", 1, 1, "key.png"},
+		{"key.png", 2, 3, "key-2.png"},
+		{"out/key", 1, 2, "out/key-1"},
+	}
+	for _, tt := range tests {
+		if got := qrFileName(tt.name, tt.i, tt.n); got != tt.want {
+			t.Errorf("qrFileName(%q, %d, %d) = %q, want %q", tt.name, tt.i, tt.n, got, tt.want)
+		}
+	}
+}
+
+// captionFailingWriter fail writes of QR code captions
+type captionFailingWriter struct{}
+
+func (captionFailingWriter) Write(p []byte) (int, error) {
+	if bytes.HasPrefix(p, []byte("QR ")) {
+		return 0, errors.New("disk full")
+	}
+	return len(p), nil
+}
+
+func Test_qrWriterCaptionError(t *testing.T) {
+	codes := &qrWriter{size: 100, w: captionFailingWriter{}}
+	codes.Write(bytes.Repeat([]byte("aGVsbG8g"), 20))
+	if err := codes.Close(); err == nil || !strings.Contains(err.Error(), "cannot write to output: disk full") {
+		t.Errorf("Close() error = %v, want cannot write to output", err)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// tokenOptions describe tokens generated by rand command
+type tokenOptions struct {
+	size      uint
+	count     uint
+	encoding  string
+	noPadding bool
+	prefix    string
+	checksum  bool
+}
+
+// runRand print random tokens
+func runRand(programName string, args []string) error {
+	flags := flag.NewFlagSet(programName+" rand", flag.ContinueOnError)
+	opts := &tokenOptions{}
+	flags.UintVarP(&opts.size, "bytes", "b", 32, "generate `N` random bytes for every token")
+	flags.UintVarP(&opts.count, "count", "c", 1, "generate `N` tokens, one per line")
+	flags.StringVarP(&opts.encoding, "encoding", "e", "base64", "encode tokens with `ENC` base64, base64url, base32,\nbase32hex, base16, hex (lower case) or base58")
+	url := flags.BoolP("url", "u", false, "same as --encoding base64url")
+	flags.BoolVarP(&opts.noPadding, "no-padding", "n", false, "omit padding")
+	flags.StringVar(&opts.prefix, "prefix", "", "start every token with `PREFIX` (e.g. myapp_)")
+	flags.BoolVar(&opts.checksum, "checksum", false, "end every token with CRC32 of prefix and random part")
+	help := flags.BoolP("help", "h", false, "print this help")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *help {
+		printRandHelp(programName, flags)
+		return nil
+	}
+
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q\nTry '%s rand --help' for more information.", flags.Arg(0), programName)
+	}
+	if *url {
+		opts.encoding = "base64url"
+	}
+	if err := generateTokens(rand.Reader, os.Stdout, opts); err != nil {
+		return fmt.Errorf("rand error: %v", err)
+	}
+	return nil
+}
+
+func printRandHelp(programName string, flags *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: %s rand [OPTION]...\n", programName)
+	fmt.Fprintf(os.Stderr, `
+Generate tokens of cryptographically secure random bytes and print them
+encoded to standard output.
+
+`)
+	flags.PrintDefaults()
+	fmt.Fprintf(os.Stderr, `
+With --checksum, big-endian CRC32 (IEEE) of prefix and random part is
+encoded by the same encoding without padding and appended to the token,
+base58 checksum is left padded with 1 to 6 characters.
+`)
+}
+
+// getTokenEncoder return function which encode data by encoding name
+func getTokenEncoder(name string, noPadding bool) (func([]byte) string, error) {
+	var encode func(input io.Reader, output io.Writer) error
+	switch name {
+	case "base64", "base64url":
+		encoding := getEncoding(noPadding, name == "base64url")
+		encode = func(input io.Reader, output io.Writer) error {
+			return xbase.Encode64(input, output, encoding, 0)
+		}
+	case "base32", "base32hex":
+		encoding := base32.StdEncoding
+		if name == "base32hex" {
+			encoding = base32.HexEncoding
+		}
+		if noPadding {
+			encoding = encoding.WithPadding(base32.NoPadding)
+		}
+		encode = func(input io.Reader, output io.Writer) error {
+			return xbase.Encode32(input, output, encoding, 0)
+		}
+	case "base16":
+		encode = func(input io.Reader, output io.Writer) error {
+			return xbase.Encode16(input, output, 0)
+		}
+	case "hex":
+		return hex.EncodeToString, nil
+	case "base58":
+		encode = func(input io.Reader, output io.Writer) error {
+			return xbase.EncodeBase58(input, output, 0)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", name)
+	}
+	return func(data []byte) string {
+		output := &strings.Builder{}
+		encode(bytes.NewReader(data), output) // writing to memory cannot fail
+		return output.String()
+	}, nil
+}
+
+// generateTokens read random bytes from random and write tokens to output
+func generateTokens(random io.Reader, output io.Writer, opts *tokenOptions) error {
+	if opts.size == 0 {
+		return fmt.Errorf("number of bytes must be positive")
+	}
+	encode, err := getTokenEncoder(opts.encoding, opts.noPadding)
+	if err != nil {
+		return err
+	}
+	encodeChecksum, err := getTokenEncoder(opts.encoding, true)
+	if err != nil {
+		return err
+	}
+
+	data := make([]byte, opts.size)
+	for i := uint(0); i < opts.count; i++ {
+		if _, err = io.ReadFull(random, data); err != nil {
+			return fmt.Errorf("cannot read random bytes: %v", err)
+		}
+		token := opts.prefix + encode(data)
+		if opts.checksum {
+			var sum [4]byte
+			binary.BigEndian.PutUint32(sum[:], crc32.ChecksumIEEE([]byte(token)))
+			checksum := encodeChecksum(sum[:])
+			if opts.encoding == "base58" && len(checksum) < 6 {
+				checksum = strings.Repeat("1", 6-len(checksum)) + checksum
+			}
+			token += checksum
+		}
+		if _, err = fmt.Fprintln(output, token); err != nil {
+			return fmt.Errorf("cannot write to output: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_generateTokens(t *testing.T) {
+	random := strings.Repeat("\xff\xfe\x00\x01", 8)
+	tests := []struct {
+		name       string
+		opts       tokenOptions
+		wantOutput string
+		wantErr    bool
+	}{
+		{"base64", tokenOptions{size: 4, count: 2, encoding: "base64"}, "//4AAQ==\n//4AAQ==\n", false},
+		{"base64url without padding", tokenOptions{size: 4, count: 1, encoding: "base64url", noPadding: true}, "__4AAQ\n", false},
+		{"base32", tokenOptions{size: 4, count: 1, encoding: "base32"}, "777AAAI=\n", false},
+		{"base32hex without padding", tokenOptions{size: 4, count: 1, encoding: "base32hex", noPadding: true}, "VVV0008\n", false},
+		{"base16", tokenOptions{size: 4, count: 1, encoding: "base16"}, "FFFE0001\n", false},
+		{"hex", tokenOptions{size: 4, count: 1, encoding: "hex"}, "fffe0001\n", false},
+		{"base58", tokenOptions{size: 4, count: 1, encoding: "base58"}, "7YXABS\n", false},
+		{"prefix", tokenOptions{size: 4, count: 1, encoding: "hex", prefix: "tok_"}, "tok_fffe0001\n", false},
+		// CRC32 of "tok_fffe0001" is 0x301c805b
+		{"checksum", tokenOptions{size: 4, count: 1, encoding: "hex", prefix: "tok_", checksum: true}, "tok_fffe0001301c805b\n", false},
+		{"no tokens", tokenOptions{size: 4, count: 0, encoding: "hex"}, "", false},
+		{"zero bytes", tokenOptions{size: 0, count: 1, encoding: "hex"}, "", true},
+		{"unsupported encoding", tokenOptions{size: 4, count: 1, encoding: "base85"}, "", true},
+		{"not enough randomness", tokenOptions{size: 64, count: 1, encoding: "hex"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			err := generateTokens(strings.NewReader(random), output, &tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generateTokens() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if output.String() != tt.wantOutput {
+				t.Errorf("generateTokens() output = %q, want %q", output, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func Test_generateTokensBase58Checksum(t *testing.T) {
+	encode, err := getTokenEncoder("base58", false)
+	if err != nil {
+		t.Fatalf("getTokenEncoder() error = %v", err)
+	}
+	for i := 0; i < 256; i++ {
+		random := bytes.Repeat([]byte{byte(i)}, 8)
+		output := &bytes.Buffer{}
+		if err = generateTokens(bytes.NewReader(random), output, &tokenOptions{size: 8, count: 1, encoding: "base58", checksum: true}); err != nil {
+			t.Fatalf("generateTokens() error = %v", err)
+		}
+		token := strings.TrimSuffix(output.String(), "\n")
+		if want := len(encode(random)) + 6; len(token) != want {
+			t.Errorf("token %q has %d characters, want %d with 6 characters of checksum", token, len(token), want)
+		}
+	}
+}
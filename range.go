@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// decodeRange decode length bytes at offset of data encoded in input to
+// output, length 0 means up to the end, input must be regular file so only
+// the range is read when its lines are wrapped regularly
+func decodeRange(input io.Reader, output io.Writer, encoding *base64.Encoding, offset, length int64) error {
+	file, ok := input.(*os.File)
+	if !ok {
+		return fmt.Errorf("--offset and --length need input FILE")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("cannot read from input: %v", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("--offset and --length need regular input FILE")
+	}
+	r, err := xbase.NewReaderAt(file, info.Size(), encoding)
+	if err != nil {
+		return err
+	}
+	if offset > r.Size() {
+		return fmt.Errorf("offset %d is beyond end of decoded data of %d bytes", offset, r.Size())
+	}
+	if length == 0 {
+		length = math.MaxInt64 - offset
+	}
+	return r.CopyRange(output, offset, length)
+}
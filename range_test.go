@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+func Test_runCodecRange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "range")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	data := make([]byte, 10000)
+	rand.New(rand.NewSource(1)).Read(data)
+	input, output := filepath.Join(dir, "input"), filepath.Join(dir, "output")
+	wrapped := &bytes.Buffer{}
+	if err := xbase.Encode64(bytes.NewReader(data), wrapped, base64.StdEncoding, 76); err != nil {
+		t.Fatalf("Encode64() error = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		input          string
+		offset, length int64
+		want           []byte
+		wantErr        string
+	}{
+		{"range", wrapped.String(), 5001, 100, data[5001:5101], ""},
+		{"up to the end", wrapped.String(), 9990, 0, data[9990:], ""},
+		{"length beyond end", wrapped.String(), 9990, 100, data[9990:], ""},
+		{"from the start", wrapped.String(), 0, 3, data[:3], ""},
+		{"CRLF", strings.Replace(wrapped.String(), "\n", "\r\n", -1), 77, 10, data[77:87], ""},
+		{"irregular wrapping", strings.Replace(wrapped.String(), "\n", "\n\n", 3), 5001, 100, data[5001:5101], ""},
+		{"offset beyond end", wrapped.String(), 10001, 0, nil, "offset 10001 is beyond end"},
+		{"invalid input", "aGVs\nbG8g\nd2!y\n", 6, 1, nil, "invalid input at byte 12 (line 3)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ioutil.WriteFile(input, []byte(tt.input), 0600); err != nil {
+				t.Fatalf("cannot write input: %v", err)
+			}
+			err := runCodec(&codecOptions{decode: true, offset: tt.offset, length: tt.length, output: output}, input)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("runCodec() error = %v, want nil", err)
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("runCodec() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			got, err := ioutil.ReadFile(output)
+			if err != nil {
+				t.Fatalf("cannot read output: %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("decoded range differs, %d bytes, want %d", len(got), len(tt.want))
+			}
+		})
+	}
+
+	for _, opts := range []codecOptions{
+		{offset: 1},
+		{decode: true, offset: -1},
+		{decode: true, offset: 1, ignoreGarbage: true},
+		{decode: true, length: 1, compression: "gzip"},
+		{decode: true, offset: 1, literal: new(string)},
+	} {
+		if err := runCodec(&opts, input); err == nil {
+			t.Errorf("runCodec(%+v) error = nil", opts)
+		}
+	}
+}
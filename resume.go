@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// resumeOverlap is number of already decoded bytes which are decoded again
+// and compared with output, so resuming from different input fails
+const resumeOverlap = 48
+
+// resumeDecoding seek input to the position of base64 data where decoding
+// of output continues and output to its end, it return writer of decoded
+// data which checks the overlap of already decoded bytes and pass the rest
+// to w
+func resumeDecoding(input io.Reader, output io.Writer, w io.WriteCloser) (io.WriteCloser, error) {
+	in, ok := input.(*os.File)
+	if !ok {
+		return nil, fmt.Errorf("--resume needs seekable input")
+	}
+	out, ok := output.(*os.File)
+	if !ok {
+		return nil, fmt.Errorf("--resume needs output FILE")
+	}
+	info, err := out.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("cannot resume: %v", err)
+	}
+	lineLength, newlineLength, err := xbase.DetectWrap(in)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resume, input must be seekable FILE: %v", err)
+	}
+
+	decoded, encoded := resumeOffset(info.Size(), lineLength, newlineLength)
+	if _, err = in.Seek(encoded, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("cannot resume, input is not seekable: %v", err)
+	}
+	expected := make([]byte, info.Size()-decoded)
+	if _, err = out.ReadAt(expected, decoded); err != nil {
+		return nil, fmt.Errorf("cannot resume: %v", err)
+	}
+	if _, err = out.Seek(0, io.SeekEnd); err != nil {
+		return nil, fmt.Errorf("cannot resume: %v", err)
+	}
+	return &overlapWriter{expected: expected, offset: decoded, w: w}, nil
+}
+
+// resumeOffset return position in decoded data where decoding of output of
+// size bytes resumes, aligned to 3 bytes and moved back by resumeOverlap,
+// and position of the corresponding 4 characters in input wrapped regularly
+// after lineLength characters
+func resumeOffset(size, lineLength, newlineLength int64) (decoded, encoded int64) {
+	decoded = size/3*3 - resumeOverlap
+	if decoded < 0 {
+		decoded = 0
+	}
+	encoded = decoded / 3 * 4
+	if lineLength > 0 {
+		encoded += encoded / lineLength * newlineLength
+	}
+	return decoded, encoded
+}
+
+// overlapWriter compare the first bytes written to it with expected bytes
+// and pass only the rest to w
+type overlapWriter struct {
+	expected []byte
+	offset   int64 // position of expected bytes in output
+
+	w io.WriteCloser
+}
+
+func (o *overlapWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(o.expected) > 0 {
+		k := len(o.expected)
+		if k > len(p) {
+			k = len(p)
+		}
+		if i := firstDifference(o.expected[:k], p[:k]); i >= 0 {
+			return 0, fmt.Errorf("output differs from decoded input at byte %d, cannot resume", o.offset+int64(i))
+		}
+		o.expected, o.offset, p = o.expected[k:], o.offset+int64(k), p[k:]
+	}
+	if len(p) == 0 {
+		return n, nil
+	}
+	if _, err := o.w.Write(p); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Close fail when input ended before all expected bytes
+func (o *overlapWriter) Close() error {
+	if err := o.w.Close(); err != nil {
+		return err
+	}
+	if len(o.expected) > 0 {
+		return fmt.Errorf("output is longer than decoded input, cannot resume")
+	}
+	return nil
+}
+
+// firstDifference return index of the first different byte of a and b of
+// the same length or -1
+func firstDifference(a, b []byte) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return -1
+}
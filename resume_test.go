@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+func Test_resumeOffset(t *testing.T) {
+	tests := []struct {
+		name                     string
+		size, line, newline      int64
+		wantDecoded, wantEncoded int64
+	}{
+		{"empty output", 0, 76, 1, 0, 0},
+		{"output shorter than overlap", 40, 76, 1, 0, 0},
+		{"not wrapped", 1000, 0, 0, 951, 1268},
+		{"wrapped after 76", 1000, 76, 1, 951, 1284},
+		{"wrapped with CRLF", 1000, 76, 2, 951, 1300},
+		{"at line boundary", 105, 76, 1, 57, 77},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, encoded := resumeOffset(tt.size, tt.line, tt.newline)
+			if decoded != tt.wantDecoded || encoded != tt.wantEncoded {
+				t.Errorf("resumeOffset() = %d, %d, want %d, %d", decoded, encoded, tt.wantDecoded, tt.wantEncoded)
+			}
+		})
+	}
+}
+
+func Test_runCodecResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resume")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	data := make([]byte, 10000)
+	rand.New(rand.NewSource(1)).Read(data)
+	input, output := filepath.Join(dir, "input"), filepath.Join(dir, "output")
+
+	tests := []struct {
+		name      string
+		wrapAfter uint
+		partial   []byte
+		wantErr   string
+	}{
+		{"wrapped", 76, data[:5001], ""},
+		{"odd wrapping", 10, data[:7], ""},
+		{"not wrapped", 0, data[:9999], ""},
+		{"empty output", 76, nil, ""},
+		{"complete output", 76, data, ""},
+		{"different output", 76, append(data[:5000:5000], 'X'), "output differs from decoded input at byte 5000"},
+		{"longer output", 76, append(data[:len(data):len(data)], 'X'), "output is longer than decoded input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := &bytes.Buffer{}
+			if err := xbase.Encode64(bytes.NewReader(data), encoded, base64.StdEncoding, tt.wrapAfter); err != nil {
+				t.Fatalf("Encode64() error = %v", err)
+			}
+			if err := ioutil.WriteFile(input, encoded.Bytes(), 0600); err != nil {
+				t.Fatalf("cannot write input: %v", err)
+			}
+			if err := ioutil.WriteFile(output, tt.partial, 0600); err != nil {
+				t.Fatalf("cannot write output: %v", err)
+			}
+
+			err := runCodec(&codecOptions{decode: true, resume: true, output: output}, input)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("runCodec() error = %v, want nil", err)
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("runCodec() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			got, err := ioutil.ReadFile(output)
+			if err != nil {
+				t.Fatalf("cannot read output: %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("resumed output differs from data, %d bytes, want %d", len(got), len(data))
+			}
+		})
+	}
+
+	for _, opts := range []codecOptions{
+		{resume: true, output: output},
+		{decode: true, resume: true},
+		{decode: true, resume: true, output: output, ignoreGarbage: true},
+		{decode: true, resume: true, output: output, compression: "gzip"},
+	} {
+		if err := runCodec(&opts, input); err == nil {
+			t.Errorf("runCodec(%+v) error = nil", opts)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	flag "github.com/spf13/pflag"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// runSecret decode data of Kubernetes Secret manifests into stringData or
+// with --encode encode stringData into data
+func runSecret(programName string, args []string) error {
+	flags := flag.NewFlagSet(programName+" secret", flag.ContinueOnError)
+	var (
+		encode = flags.BoolP("encode", "e", false, "encode stringData values into data")
+		help   = flags.BoolP("help", "h", false, "print this help")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *help {
+		printSecretHelp(programName, flags)
+		return nil
+	}
+
+	file, err := getFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err = transformSecrets(file, os.Stdout, *encode, os.Stderr); err != nil {
+		return fmt.Errorf("secret pipeline error: %v", err)
+	}
+	return nil
+}
+
+func printSecretHelp(programName string, flags *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: %s secret [OPTION]... [FILE]\n", programName)
+	fmt.Fprintf(os.Stderr, `
+Decode data of Kubernetes Secret manifests in YAML or JSON from FILE, or
+standard input, into stringData and write them to standard output.
+With no FILE, or when FILE is -, read standard input.
+
+`)
+	flags.PrintDefaults()
+	fmt.Fprintf(os.Stderr, `
+Values which are not valid UTF-8 after decoding are left encoded in data.
+Secrets inside a List (e.g. kubectl get secrets -o yaml) are processed too.
+YAML comments are not preserved.
+`)
+}
+
+// transformSecrets read YAML or JSON manifests from input, move values of
+// every Secret between data and stringData and write them to output
+func transformSecrets(input io.Reader, output io.Writer, encode bool, warnings io.Writer) error {
+	manifests, err := ioutil.ReadAll(input)
+	if err != nil {
+		return fmt.Errorf("cannot read from input: %v", err)
+	}
+
+	transform := func(value interface{}) (interface{}, error) {
+		return transformSecret(value, encode, warnings)
+	}
+
+	if trimmed := bytes.TrimSpace(manifests); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return forEachJSONDocument(bytes.NewReader(manifests), output, transform)
+	}
+	return forEachYAMLDocument(bytes.NewReader(manifests), output, transform)
+}
+
+// forEachYAMLDocument read YAML documents from input, replace each of them
+// with the result of transform and write it to output preserving order of keys
+func forEachYAMLDocument(input io.Reader, output io.Writer, transform func(interface{}) (interface{}, error)) error {
+	decoder := yaml.NewDecoder(input)
+	for n := 1; ; n++ {
+		var document yaml.MapSlice
+		if err := decoder.Decode(&document); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("cannot read YAML document %d: %v", n, err)
+		}
+
+		value, err := transform(fromYAML(document))
+		if err != nil {
+			return fmt.Errorf("YAML document %d: %v", n, err)
+		}
+
+		result, err := yaml.Marshal(toYAML(value))
+		if err != nil {
+			return fmt.Errorf("cannot write YAML document %d: %v", n, err)
+		}
+		if n > 1 {
+			result = append([]byte("---\n"), result...)
+		}
+		if _, err = output.Write(result); err != nil {
+			return fmt.Errorf("cannot write to output: %v", err)
+		}
+	}
+}
+
+// fromYAML convert ordered YAML mappings into jsonObject
+func fromYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		object := make(jsonObject, 0, len(v))
+		for _, item := range v {
+			object = append(object, jsonMember{key: fmt.Sprint(item.Key), value: fromYAML(item.Value)})
+		}
+		return object
+	case []interface{}:
+		for i := range v {
+			v[i] = fromYAML(v[i])
+		}
+	}
+	return value
+}
+
+// toYAML convert jsonObject back into ordered YAML mappings
+func toYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case jsonObject:
+		mapping := make(yaml.MapSlice, 0, len(v))
+		for _, member := range v {
+			mapping = append(mapping, yaml.MapItem{Key: member.key, Value: toYAML(member.value)})
+		}
+		return mapping
+	case []interface{}:
+		for i := range v {
+			v[i] = toYAML(v[i])
+		}
+	}
+	return value
+}
+
+// transformSecret move values between data and stringData of a Secret or of
+// every Secret in items of a List, other manifests are returned unchanged
+func transformSecret(value interface{}, encode bool, warnings io.Writer) (interface{}, error) {
+	object, ok := value.(jsonObject)
+	if !ok {
+		return value, nil
+	}
+
+	if items, ok := object.get("items").([]interface{}); ok {
+		for i := range items {
+			item, err := transformSecret(items[i], encode, warnings)
+			if err != nil {
+				return nil, fmt.Errorf("items[%d]: %v", i, err)
+			}
+			items[i] = item
+		}
+		return object, nil
+	}
+
+	if kind, _ := object.get("kind").(string); kind != "Secret" {
+		return object, nil
+	}
+
+	name := "<unnamed>"
+	if metadata, ok := object.get("metadata").(jsonObject); ok {
+		if n, ok := metadata.get("name").(string); ok {
+			name = n
+		}
+	}
+
+	var err error
+	if encode {
+		object, err = moveSecretValues(object, "stringData", "data", func(key, value string) (string, bool, error) {
+			encoded := &bytes.Buffer{}
+			err := xbase.Encode64(strings.NewReader(value), encoded, base64.StdEncoding, 0)
+			return encoded.String(), true, err
+		})
+	} else {
+		object, err = moveSecretValues(object, "data", "stringData", func(key, value string) (string, bool, error) {
+			decoded := &bytes.Buffer{}
+			if err := xbase.Decode64(strings.NewReader(value), decoded, base64.StdEncoding, false); err != nil {
+				return "", false, err
+			}
+			if !utf8.Valid(decoded.Bytes()) {
+				fmt.Fprintf(warnings, "secret %s: leaving data.%s encoded, it is not valid UTF-8\n", name, key)
+				return "", false, nil
+			}
+			return decoded.String(), true, nil
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: %v", name, err)
+	}
+	return object, nil
+}
+
+// moveSecretValues convert values of object member from with convert and move
+// them to member to, values which convert does not move are kept in from
+func moveSecretValues(object jsonObject, from, to string, convert func(key, value string) (string, bool, error)) (jsonObject, error) {
+	source, ok := object.get(from).(jsonObject)
+	if !ok && object.get(from) != nil {
+		return nil, fmt.Errorf("%s is not an object", from)
+	}
+	if len(source) == 0 {
+		return object, nil
+	}
+	target, ok := object.get(to).(jsonObject)
+	if !ok && object.get(to) != nil {
+		return nil, fmt.Errorf("%s is not an object", to)
+	}
+
+	kept := jsonObject{}
+	for _, member := range source {
+		value, ok := member.value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s.%s is not a string", from, member.key)
+		}
+		converted, move, err := convert(member.key, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", from, member.key, err)
+		}
+		if !move {
+			kept = append(kept, member)
+			continue
+		}
+		if target.get(member.key) != nil {
+			return nil, fmt.Errorf("%s is present in both %s and %s", member.key, from, to)
+		}
+		target = append(target, jsonMember{key: member.key, value: converted})
+	}
+
+	if object.index(to) < 0 {
+		object = object.insert(object.index(from)+1, to, target)
+	} else {
+		object[object.index(to)].value = target
+	}
+	if len(kept) > 0 {
+		object[object.index(from)].value = kept
+	} else {
+		object = object.remove(from)
+	}
+	if len(target) == 0 {
+		object = object.remove(to)
+	}
+	return object, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_transformSecrets(t *testing.T) {
+	type args struct {
+		input  string
+		encode bool
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantOutput   string
+		wantWarnings string
+		wantErr      bool
+	}{
+		{
+			"decode YAML Secret keeping binary value encoded",
+			args{`apiVersion: v1
+kind: Secret
+metadata:
+  name: demo
+data:
+  password: aHVudGVyMg==
+  bin: /w==
+type: Opaque
+`, false},
+			`apiVersion: v1
+kind: Secret
+metadata:
+  name: demo
+data:
+  bin: /w==
+stringData:
+  password: hunter2
+type: Opaque
+`,
+			"secret demo: leaving data.bin encoded, it is not valid UTF-8\n",
+			false,
+		},
+		{
+			"encode YAML Secret merging into existing data",
+			args{`kind: Secret
+data:
+  bin: /w==
+stringData:
+  password: hunter2
+`, true},
+			`kind: Secret
+data:
+  bin: /w==
+  password: aHVudGVyMg==
+`,
+			"",
+			false,
+		},
+		{
+			"other YAML documents are kept",
+			args{`kind: ConfigMap
+data:
+  a: b
+---
+kind: Secret
+data:
+  a: YQ==
+`, false},
+			`kind: ConfigMap
+data:
+  a: b
+---
+kind: Secret
+stringData:
+  a: a
+`,
+			"",
+			false,
+		},
+		{
+			"decode JSON List of Secrets",
+			args{`{"kind":"List","items":[{"kind":"Secret","data":{"a":"YQ=="},"type":"Opaque"}]}`, false},
+			`{"kind":"List","items":[{"kind":"Secret","stringData":{"a":"a"},"type":"Opaque"}]}` + "\n",
+			"",
+			false,
+		},
+		{
+			"encode pretty printed JSON",
+			args{"{\n  \"kind\": \"Secret\",\n  \"stringData\": {\"a\": \"a\"}\n}\n", true},
+			"{\n  \"kind\": \"Secret\",\n  \"data\": {\n    \"a\": \"YQ==\"\n  }\n}\n",
+			"",
+			false,
+		},
+		{
+			"key present in both data and stringData",
+			args{`{"kind":"Secret","data":{"a":"YQ=="},"stringData":{"a":"b"}}`, false},
+			"",
+			"",
+			true,
+		},
+		{
+			"invalid base64 in data",
+			args{`{"kind":"Secret","data":{"a":"*"}}`, false},
+			"",
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, warnings := &bytes.Buffer{}, &bytes.Buffer{}
+			if err := transformSecrets(strings.NewReader(tt.args.input), output, tt.args.encode, warnings); (err != nil) != tt.wantErr {
+				t.Errorf("transformSecrets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("transformSecrets() mismatch (-got +want):\n%s", diff)
+			}
+			if diff := cmp.Diff(warnings.String(), tt.wantWarnings); diff != "" {
+				t.Errorf("transformSecrets() warnings mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
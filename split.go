@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// partHeader starts every part written with --split-size, checksum is SHA-256
+// of all encoded data without newlines
+const partHeader = "part %d/%d sha256:%x\n"
+
+var partHeaderRE = regexp.MustCompile(`^part (\d+)/(\d+) sha256:([0-9a-f]{64})$`)
+
+// splitWriter collect encoded data and write them as parts of at most size
+// bytes on Close, as number of parts must be known in advance
+type splitWriter struct {
+	buffer bytes.Buffer
+	size   uint
+
+	w io.Writer
+}
+
+func (s *splitWriter) Write(p []byte) (int, error) {
+	return s.buffer.Write(p)
+}
+
+func (s *splitWriter) Close() error {
+	return writeParts(s.w, s.buffer.Bytes(), s.size)
+}
+
+// writeParts split encoded data to parts of at most size bytes including
+// header, parts are separated by empty line
+func writeParts(output io.Writer, encoded []byte, size uint) error {
+	parts, err := splitParts(encoded, size)
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(output, strings.Join(parts, "\n")); err != nil {
+		return fmt.Errorf("cannot write to output: %v", err)
+	}
+	return nil
+}
+
+// splitParts split encoded data to parts of at most size bytes, every part
+// starts with header and ends with newline
+func splitParts(encoded []byte, size uint) ([]string, error) {
+	sum := sha256.Sum256(bytes.Replace(encoded, []byte{'\n'}, nil, -1))
+
+	// header is longer with more parts, so split until number of parts fits
+	var chunks [][]byte
+	for n := 1; ; {
+		capacity := int(size) - len(fmt.Sprintf(partHeader, n, n, sum)) - 1
+		if capacity < 1 {
+			return nil, fmt.Errorf("--split-size %d is too small for part header", size)
+		}
+		chunks = splitEncoded(encoded, capacity)
+		if len(chunks) <= n {
+			break
+		}
+		n = len(chunks)
+	}
+
+	parts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		if len(chunk) > 0 && chunk[len(chunk)-1] != '\n' {
+			chunk = append(chunk[:len(chunk):len(chunk)], '\n')
+		}
+		parts[i] = fmt.Sprintf(partHeader+"%s", i+1, len(chunks), sum, chunk)
+	}
+	return parts, nil
+}
+
+// splitEncoded cut data to chunks of capacity bytes, newlines at the start of
+// chunks are dropped, there is always at least one chunk
+func splitEncoded(data []byte, capacity int) [][]byte {
+	chunks := [][]byte{}
+	for len(data) > 0 || len(chunks) == 0 {
+		n := capacity
+		if n > len(data) {
+			n = len(data)
+		}
+		chunks = append(chunks, data[:n])
+		data = bytes.TrimLeft(data[n:], "\n")
+	}
+	return chunks
+}
+
+// joinParts read parts written by writeParts in any order from input and
+// return encoded data of all parts after checking that no part is missing
+// and checksum matches
+func joinParts(input io.Reader) ([]byte, error) {
+	var (
+		parts   = map[int][][]byte{} // all copies of every part
+		total   int
+		sum     string
+		current = -1
+	)
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		match := partHeaderRE.FindStringSubmatch(text)
+		if match == nil {
+			if current < 0 {
+				return nil, fmt.Errorf("line %d: data before the first part header", line)
+			}
+			copies := parts[current]
+			copies[len(copies)-1] = append(copies[len(copies)-1], text...)
+			continue
+		}
+
+		index, _ := strconv.Atoi(match[1])
+		n, _ := strconv.Atoi(match[2])
+		switch {
+		case total == 0:
+			total, sum = n, match[3]
+		case n != total || match[3] != sum:
+			return nil, fmt.Errorf("line %d: part of other data, expected %d parts with sha256:%s", line, total, sum)
+		}
+		if index < 1 || index > total {
+			return nil, fmt.Errorf("line %d: invalid part %d/%d", line, index, n)
+		}
+		// the same part pasted twice is fine, copies are compared at the end
+		current = index
+		parts[current] = append(parts[current], []byte{})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read input: %v", err)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("no part header found")
+	}
+
+	var missing []string
+	joined := []byte{}
+	for i := 1; i <= total; i++ {
+		copies, ok := parts[i]
+		if !ok {
+			missing = append(missing, strconv.Itoa(i))
+			continue
+		}
+		for _, c := range copies[1:] {
+			if !bytes.Equal(c, copies[0]) {
+				return nil, fmt.Errorf("part %d is given more times with different content", i)
+			}
+		}
+		joined = append(joined, copies[0]...)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing part %s of %d", strings.Join(missing, ", "), total)
+	}
+	if got := sha256.Sum256(joined); hex.EncodeToString(got[:]) != sum {
+		return nil, fmt.Errorf("checksum mismatch, parts are corrupted")
+	}
+	return joined, nil
+}
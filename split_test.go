@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_writeParts(t *testing.T) {
+	tests := []struct {
+		name      string
+		encoded   string
+		size      uint
+		wantParts int
+		wantErr   bool
+	}{
+		{"one part", "aGVsbG8gd29ybGQ=\n", 200, 1, false},
+		{"parts of wrapped data", "aGVs\nbG8g\nd29y\nbGQ=\n", 87, 4, false},
+		{"empty input", "", 100, 1, false},
+		{"more parts than 9", strings.Repeat("QUFB", 30) + "\n", 90, 20, false},
+		{"too small", "aGVsbG8=\n", 80, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			err := writeParts(output, []byte(tt.encoded), tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("writeParts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			parts := strings.Split(output.String(), "\n\n")
+			if len(parts) != tt.wantParts {
+				t.Errorf("writeParts() parts = %d, want %d:\n%s", len(parts), tt.wantParts, output)
+			}
+			for _, part := range parts {
+				if size := len(strings.TrimSuffix(part, "\n")) + 1; size > int(tt.size) {
+					t.Errorf("part has %d bytes, want at most %d:\n%s", size, tt.size, part)
+				}
+			}
+
+			joined, err := joinParts(output)
+			if err != nil {
+				t.Fatalf("joinParts() error = %v", err)
+			}
+			if want := strings.Replace(tt.encoded, "\n", "", -1); string(joined) != want {
+				t.Errorf("joinParts() = %q, want %q", joined, want)
+			}
+		})
+	}
+}
+
+func Test_joinParts(t *testing.T) {
+	output := &bytes.Buffer{}
+	if err := writeParts(output, []byte("aGVs\nbG8g\nd29y\nbGQ=\n"), 87); err != nil {
+		t.Fatalf("writeParts() error = %v", err)
+	}
+	parts := strings.SplitAfter(output.String(), "\n\n")
+	other := &bytes.Buffer{}
+	if err := writeParts(other, []byte("aGk=\n"), 100); err != nil {
+		t.Fatalf("writeParts() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{"any order", parts[2] + parts[0] + "\n\n" + parts[3] + "\n" + parts[1], "aGVsbG8gd29ybGQ=", ""},
+		{"part pasted twice", parts[0] + parts[1] + parts[1] + parts[2] + parts[3], "aGVsbG8gd29ybGQ=", ""},
+		{"missing parts", parts[1] + parts[3], "", "missing part 1, 3 of 4"},
+		{"different copies", parts[0] + parts[1] + strings.Replace(parts[1], "bG8g", "bG8h", 1) + parts[2] + parts[3], "", "part 2 is given more times"},
+		{"corrupted part", parts[0] + strings.Replace(parts[1], "bG8g", "bG8h", 1) + parts[2] + parts[3], "", "checksum mismatch"},
+		{"parts of other data", parts[0] + other.String(), "", "part of other data"},
+		{"data before header", "aGk=\n" + parts[0], "", "line 1: data before the first part header"},
+		{"invalid index", strings.Replace(parts[0], "part 1/4", "part 5/4", 1), "", "invalid part 5/4"},
+		{"no header", "", "", "no part header found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := joinParts(strings.NewReader(tt.input))
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("joinParts() error = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("joinParts() error = %v, want %q", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("joinParts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
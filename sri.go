@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+	"golang.org/x/net/html"
+)
+
+// sriStrength orders hash functions allowed by Subresource Integrity, only
+// the strongest ones in integrity metadata are checked
+var sriStrength = map[string]int{"sha256": 1, "sha384": 2, "sha512": 3}
+
+// runSRI print Subresource Integrity strings of files or verify integrity
+// attributes of HTML file
+func runSRI(programName string, args []string) error {
+	flags := flag.NewFlagSet(programName+" sri", flag.ContinueOnError)
+	var (
+		algorithms = flags.StringSliceP("algorithm", "a", []string{"sha384"}, "hash with `ALG` sha256, sha384 or sha512,\ncan be repeated or comma separated")
+		verify     = flags.String("verify", "", "check integrity attributes of <script> and <link>\nelements in `HTML` file against local files")
+		help       = flags.BoolP("help", "h", false, "print this help")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *help {
+		printSRIHelp(programName, flags)
+		return nil
+	}
+
+	if *verify != "" {
+		return verifySRI(*verify, os.Stdout)
+	}
+
+	fileNames := flags.Args()
+	if len(fileNames) == 0 {
+		fileNames = []string{"-"}
+	}
+	for _, fileName := range fileNames {
+		file, err := getFile(fileName)
+		if err != nil {
+			return err
+		}
+		integrity, err := computeSRI(file, *algorithms)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("sri error: %s: %v", fileName, err)
+		}
+		if len(fileNames) == 1 {
+			fmt.Fprintln(os.Stdout, integrity)
+		} else {
+			fmt.Fprintf(os.Stdout, "%s  %s\n", integrity, fileName)
+		}
+	}
+	return nil
+}
+
+func printSRIHelp(programName string, flags *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: %s sri [OPTION]... [FILE]...\n", programName)
+	fmt.Fprintf(os.Stderr, `
+Print Subresource Integrity string (e.g. sha384-...) of each FILE, or
+standard input, to standard output. With more FILEs every string is followed
+by the file name.
+With no FILE, or when FILE is -, read standard input.
+
+`)
+	flags.PrintDefaults()
+	fmt.Fprintf(os.Stderr, `
+With --verify, src of <script> and href of <link> elements with integrity
+attribute are resolved relative to directory of HTML file, remote resources
+are skipped.
+`)
+}
+
+// computeSRI return integrity metadata of input with hash for every algorithm
+func computeSRI(input io.Reader, algorithms []string) (string, error) {
+	if len(algorithms) == 0 {
+		return "", fmt.Errorf("no algorithm given")
+	}
+	hashes := make([]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, len(algorithms))
+	for i, alg := range algorithms {
+		if sriStrength[alg] == 0 {
+			return "", fmt.Errorf("unsupported algorithm %q, use sha256, sha384 or sha512", alg)
+		}
+		hashes[i] = digestAlgorithms[alg]()
+		writers[i] = hashes[i]
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), input); err != nil {
+		return "", fmt.Errorf("cannot read from input: %v", err)
+	}
+
+	metadata := make([]string, len(algorithms))
+	for i, alg := range algorithms {
+		metadata[i] = alg + "-" + base64.StdEncoding.EncodeToString(hashes[i].Sum(nil))
+	}
+	return strings.Join(metadata, " "), nil
+}
+
+// checkIntegrity report whether data match integrity metadata, as browsers do
+// only hashes of the strongest algorithm are used and any of them must match
+func checkIntegrity(integrity string, data []byte) (bool, error) {
+	var (
+		strongest string
+		expected  []string
+	)
+	for _, metadata := range strings.Fields(integrity) {
+		if i := strings.IndexByte(metadata, '?'); i >= 0 {
+			metadata = metadata[:i] // drop options
+		}
+		i := strings.IndexByte(metadata, '-')
+		if i < 0 || sriStrength[metadata[:i]] == 0 {
+			continue
+		}
+		switch alg := metadata[:i]; {
+		case sriStrength[alg] > sriStrength[strongest]:
+			strongest, expected = alg, []string{metadata[i+1:]}
+		case alg == strongest:
+			expected = append(expected, metadata[i+1:])
+		}
+	}
+	if strongest == "" {
+		return false, fmt.Errorf("no supported hash in integrity %q", integrity)
+	}
+
+	h := digestAlgorithms[strongest]()
+	h.Write(data)
+	sum := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	for _, e := range expected {
+		if e == sum {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// sriResource is a resource referenced by HTML element with integrity attribute
+type sriResource struct {
+	ref       string
+	integrity string
+}
+
+// findSRIResources return resources of <script> and <link> elements with
+// integrity attribute in HTML document
+func findSRIResources(document io.Reader) ([]sriResource, error) {
+	var resources []sriResource
+	tokenizer := html.NewTokenizer(document)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return resources, nil
+			}
+			return nil, tokenizer.Err()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			refAttr := map[string]string{"script": "src", "link": "href"}[token.Data]
+			if refAttr == "" {
+				continue
+			}
+			var resource sriResource
+			var hasIntegrity bool
+			for _, attr := range token.Attr {
+				switch attr.Key {
+				case refAttr:
+					resource.ref = attr.Val
+				case "integrity":
+					resource.integrity, hasIntegrity = attr.Val, true
+				}
+			}
+			if hasIntegrity && resource.ref != "" {
+				resources = append(resources, resource)
+			}
+		}
+	}
+}
+
+// verifySRI check integrity attributes in htmlFile against local files and
+// write result of every check to output
+func verifySRI(htmlFile string, output io.Writer) error {
+	document, err := ioutil.ReadFile(filepath.Clean(htmlFile))
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %v", htmlFile, err)
+	}
+	resources, err := findSRIResources(bytes.NewReader(document))
+	if err != nil {
+		return fmt.Errorf("cannot parse %s: %v", htmlFile, err)
+	}
+
+	var failed int
+	for _, resource := range resources {
+		ref, err := url.Parse(resource.ref)
+		if err != nil {
+			fmt.Fprintf(output, "%s: FAILED (invalid URL: %v)\n", resource.ref, err)
+			failed++
+			continue
+		}
+		if ref.Scheme != "" || ref.Host != "" {
+			fmt.Fprintf(output, "%s: SKIPPED (remote resource)\n", resource.ref)
+			continue
+		}
+		path := filepath.Join(filepath.Dir(htmlFile), filepath.FromSlash(strings.TrimPrefix(ref.Path, "/")))
+		data, err := ioutil.ReadFile(filepath.Clean(path))
+		if err != nil {
+			fmt.Fprintf(output, "%s: FAILED (%v)\n", resource.ref, err)
+			failed++
+			continue
+		}
+		ok, err := checkIntegrity(resource.integrity, data)
+		switch {
+		case err != nil:
+			fmt.Fprintf(output, "%s: FAILED (%v)\n", resource.ref, err)
+			failed++
+		case !ok:
+			fmt.Fprintf(output, "%s: FAILED\n", resource.ref)
+			failed++
+		default:
+			fmt.Fprintf(output, "%s: OK\n", resource.ref)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d integrity checks failed", failed, len(resources))
+	}
+	return nil
+}
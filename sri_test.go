@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	helloSRI256 = "sha256-LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="
+	helloSRI384 = "sha384-WeF0h3dEjGnea4ANejO7+5/xtGPkQ1TDVTvNucZm+pASWjx5+QOXvfX2oT3oKGhP"
+)
+
+func Test_computeSRI(t *testing.T) {
+	tests := []struct {
+		name       string
+		algorithms []string
+		want       string
+		wantErr    bool
+	}{
+		{"sha384", []string{"sha384"}, helloSRI384, false},
+		{"more algorithms", []string{"sha256", "sha384"}, helloSRI256 + " " + helloSRI384, false},
+		{"unsupported algorithm", []string{"blake2b"}, "", true},
+		{"no algorithm", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := computeSRI(strings.NewReader("hello"), tt.algorithms)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("computeSRI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("computeSRI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkIntegrity(t *testing.T) {
+	tests := []struct {
+		name      string
+		integrity string
+		want      bool
+		wantErr   bool
+	}{
+		{"match", helloSRI384, true, false},
+		{"mismatch", "sha384-AAAA", false, false},
+		{"only the strongest algorithm is checked", "sha256-AAAA " + helloSRI384, true, false},
+		{"weaker algorithm is ignored", helloSRI256 + " sha384-AAAA", false, false},
+		{"any hash of the strongest algorithm", "sha384-AAAA " + helloSRI384, true, false},
+		{"options are ignored", helloSRI384 + "?foo", true, false},
+		{"unknown algorithms are ignored", "md5-AAAA " + helloSRI256, true, false},
+		{"no supported hash", "md5-AAAA", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkIntegrity(tt.integrity, []byte("hello"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkIntegrity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("checkIntegrity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_verifySRI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sri")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	files := map[string]string{
+		"js/app.js":  "hello",
+		"style.css":  "hello",
+		"changed.js": "hello!",
+		"index.html": `<!DOCTYPE html>
+<html><head>
+<link rel="stylesheet" href="/style.css?v=1" integrity="` + helloSRI384 + `">
+<script src="https://cdn.example.com/lib.js" integrity="sha384-AAAA" crossorigin="anonymous"></script>
+<script src="js/app.js" integrity="` + helloSRI256 + `"></script>
+<script src="changed.js" integrity="` + helloSRI256 + `"></script>
+<script src="missing.js" integrity="` + helloSRI256 + `"></script>
+<script src="plain.js"></script>
+</head></html>
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("cannot create directory: %v", err)
+		}
+		if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("cannot write %s: %v", name, err)
+		}
+	}
+
+	output := &bytes.Buffer{}
+	err = verifySRI(filepath.Join(dir, "index.html"), output)
+	if err == nil || err.Error() != "2 of 5 integrity checks failed" {
+		t.Errorf("verifySRI() error = %v, want 2 of 5 integrity checks failed", err)
+	}
+	gotLines := strings.Split(output.String(), "\n")
+	wantLines := []string{
+		"/style.css?v=1: OK",
+		"https://cdn.example.com/lib.js: SKIPPED (remote resource)",
+		"js/app.js: OK",
+		"changed.js: FAILED",
+		"missing.js: FAILED",
+		"",
+	}
+	if len(gotLines) == len(wantLines) {
+		// error of missing file depends on OS
+		gotLines[4] = strings.SplitN(gotLines[4], " (", 2)[0]
+	}
+	if diff := cmp.Diff(wantLines, gotLines); diff != "" {
+		t.Errorf("verifySRI() output mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var errBinaryOutput = errors.New("refusing to write binary data to terminal, use --hexdump or --force")
+
+// isTerminal return true when file is a terminal, not only a character
+// device, so /dev/null behaves like any other redirection, tests replace it
+var isTerminal = func(file *os.File) bool {
+	return terminal.IsTerminal(int(file.Fd()))
+}
+
+// binaryGuard pass only text to w and fail with errBinaryOutput on binary
+// data, so control sequences in decoded data cannot mess up terminal
+type binaryGuard struct {
+	pending []byte // incomplete UTF-8 sequence at the end of previous write
+
+	w io.Writer
+}
+
+func (g *binaryGuard) Write(p []byte) (n int, err error) {
+	data := append(g.pending, p...)
+
+	// keep incomplete UTF-8 sequence for the next write
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+
+	if isBinary(data[:cut]) {
+		return 0, errBinaryOutput
+	}
+	if _, err = g.w.Write(data[:cut]); err != nil {
+		return 0, err
+	}
+	g.pending = append([]byte(nil), data[cut:]...)
+	return len(p), nil
+}
+
+// Close fail when data ended with incomplete UTF-8 sequence
+func (g *binaryGuard) Close() error {
+	if len(g.pending) > 0 {
+		return errBinaryOutput
+	}
+	return nil
+}
+
+// isBinary return true for data which is not valid UTF-8 or contains control
+// characters other than whitespace
+func isBinary(data []byte) bool {
+	if !utf8.Valid(data) {
+		return true
+	}
+	for _, v := range data {
+		if (v < 0x20 && v != '\t' && v != '\n' && v != '\r' && v != '\f' && v != '\v') || v == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func Test_binaryGuard(t *testing.T) {
+	tests := []struct {
+		name       string
+		writes     []string
+		wantOutput string
+		wantErr    bool
+	}{
+		{"text", []string{"hello\tworld\r\n"}, "hello\tworld\r\n", false},
+		{"UTF-8 split across writes", []string{"lo\xc2", "\xa3 ok"}, "lo£ ok", false},
+		{"NUL byte", []string{"hello", "\x00world"}, "hello", true},
+		{"escape sequence", []string{"\x1b[2J"}, "", true},
+		{"invalid UTF-8", []string{"\xff\xfe"}, "", true},
+		{"incomplete UTF-8 at the end", []string{"lo\xc2"}, "lo", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			guard := &binaryGuard{w: output}
+			var err error
+			for _, w := range tt.writes {
+				if _, err = guard.Write([]byte(w)); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = guard.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("binaryGuard error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotOutput := output.String(); gotOutput != tt.wantOutput {
+				t.Errorf("binaryGuard output = %q, want %q", gotOutput, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func Test_isTerminal(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "example")
+	if err != nil {
+		t.Fatalf("cannot create temporary file: %v", err)
+	}
+	defer os.Remove(tmpfile.Name()) // clean up
+	defer tmpfile.Close()
+
+	if isTerminal(tmpfile) {
+		t.Errorf("isTerminal(%s) = true, want false", tmpfile.Name())
+	}
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("cannot open %s: %v", os.DevNull, err)
+	}
+	defer devNull.Close()
+	if isTerminal(devNull) {
+		t.Errorf("isTerminal(%s) = true, want false", os.DevNull)
+	}
+}
+
+func Test_runCodecBinaryToDevNull(t *testing.T) {
+	binary := "AAEC/w=="
+	if err := runCodec(&codecOptions{decode: true, literal: &binary, output: os.DevNull}, ""); err != nil {
+		t.Errorf("runCodec() error = %v, want nil", err)
+	}
+}
+
+func Test_runCodecHexdumpWhenEncoding(t *testing.T) {
+	literal := "aGk="
+	err := runCodec(&codecOptions{literal: &literal, hexdump: true, wrapAfter: 76}, "")
+	if err == nil || !strings.Contains(err.Error(), "--hexdump can be used only when decoding") {
+		t.Errorf("runCodec() error = %v, want --hexdump error", err)
+	}
+}
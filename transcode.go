@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/base32"
+	"fmt"
+	"io"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// transcodeEncodings are encodings of GNU basenc and base58
+var transcodeEncodings = append(basencEncodings[:len(basencEncodings):len(basencEncodings)], basencEncoding{
+	"base58", "Bitcoin base58 encoding",
+	xbase.EncodeBase58, xbase.DecodeBase58,
+})
+
+// getTranscodeEncoding return encoding with name, output of base64 and base32
+// is not padded with noPadding
+func getTranscodeEncoding(name string, noPadding bool) (basencEncoding, error) {
+	for _, e := range transcodeEncodings {
+		if e.name != name {
+			continue
+		}
+		switch name {
+		case "base64", "base64url":
+			// decode with offsets of original input, padding is optional
+			encoding := getEncoding(noPadding, name == "base64url")
+			e.encode = func(input io.Reader, output io.Writer, wrapAfter uint) error {
+				return xbase.Encode64(input, output, encoding, wrapAfter)
+			}
+			e.decode = func(input io.Reader, output io.Writer, ignoreGarbage bool) error {
+				return xbase.Decode64Tracked(input, output, getEncoding(false, name == "base64url"), ignoreGarbage)
+			}
+		case "base32", "base32hex":
+			if noPadding {
+				encoding := base32.StdEncoding
+				if name == "base32hex" {
+					encoding = base32.HexEncoding
+				}
+				encoding = encoding.WithPadding(base32.NoPadding)
+				e.encode = func(input io.Reader, output io.Writer, wrapAfter uint) error {
+					return xbase.Encode32(input, output, encoding, wrapAfter)
+				}
+			}
+		}
+		return e, nil
+	}
+	return basencEncoding{}, fmt.Errorf("unsupported encoding %q", name)
+}
+
+// transcode decode input encoded by from and encode it by to in one
+// streaming pipeline, decoding errors are reported before encoding ones
+func transcode(input io.Reader, output io.Writer, from, to basencEncoding, ignoreGarbage bool, wrapAfter uint) error {
+	pr, pw := io.Pipe()
+	decoded := make(chan error, 1)
+	go func() {
+		err := from.decode(input, pw, ignoreGarbage)
+		pw.CloseWithError(err)
+		decoded <- err
+	}()
+
+	err := to.encode(pr, output, wrapAfter)
+	pr.Close() // unblock decoder when encoder failed
+	if derr := <-decoded; derr != nil {
+		return fmt.Errorf("decode pipeline error: %v", derr)
+	}
+	if err != nil {
+		return fmt.Errorf("encode pipeline error: %v", err)
+	}
+	return nil
+}
+
+// runTranscode transcode fileName, or standard input, from one encoding to
+// another to standard output
+func runTranscode(opts *codecOptions, fileName string) error {
+	switch {
+	case opts.decode:
+		return fmt.Errorf("--from and --to cannot be used with --decode")
+	case opts.jsonPath != "" || opts.lines || opts.hexdump:
+		return fmt.Errorf("--from and --to cannot be used with --json-path, --lines or --hexdump")
+	case opts.compression != "" || opts.digest != "" || opts.expectDigest != "" || opts.encrypt || opts.decrypt:
+		return fmt.Errorf("--from and --to cannot be used with compression, digest or encryption")
+	case opts.splitSize > 0 || opts.join || opts.archive != "":
+		return fmt.Errorf("--from and --to cannot be used with --split-size, --join or --archive")
+	}
+
+	from, err := getTranscodeEncoding(opts.from, false)
+	if err != nil {
+		return fmt.Errorf("--from: %v", err)
+	}
+	to, err := getTranscodeEncoding(opts.to, opts.noPadding)
+	if err != nil {
+		return fmt.Errorf("--to: %v", err)
+	}
+
+	stdout, flush, err := openOutput(opts)
+	if err != nil {
+		return err
+	}
+	file, err := openInput(opts, fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err = transcode(file, stdout, from, to, opts.ignoreGarbage, opts.wrapAfter); err != nil {
+		return err
+	}
+	return flush()
+}
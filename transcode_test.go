@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_transcode(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		from          string
+		to            string
+		noPadding     bool
+		ignoreGarbage bool
+		wrapAfter     uint
+		wantOutput    string
+		wantErr       string
+	}{
+		{"base64 to URL without padding", "aGVsbG8gd29ybGQ/Pg==\n", "base64", "base64url", true, false, 76, "aGVsbG8gd29ybGQ_Pg\n", ""},
+		{"unpadded base64 to base16", "aGVsbG8", "base64", "base16", false, false, 0, "68656C6C6F", ""},
+		{"base16 to base58", "68656C6C6F\n", "base16", "base58", false, false, 76, "Cn8eVZg\n", ""},
+		{"base32 to wrapped base64", "NBSWY3DPEB3W64TMMQ======\n", "base32", "base64", false, false, 8, "aGVsbG8g\nd29ybGQ=\n", ""},
+		{"base32 without padding", "aGVsbG8=", "base64", "base32", true, false, 0, "NBSWY3DP", ""},
+		{"garbage is ignored, z85 needs whole groups", "aGV*sbG8=", "base64", "z85", false, true, 0, "xK#0@", "encode pipeline error"},
+		{"error at original offset", "aGVs\nbG8g\nd2!y\n", "base64", "base16", false, false, 0, "68656C6C6F20", "invalid input at byte 12 (line 3)"},
+		{"error of other decoders", "6865ZZ", "base16", "base64", false, false, 0, "", "decode pipeline error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, err := getTranscodeEncoding(tt.from, false)
+			if err != nil {
+				t.Fatalf("getTranscodeEncoding(%q) error = %v", tt.from, err)
+			}
+			to, err := getTranscodeEncoding(tt.to, tt.noPadding)
+			if err != nil {
+				t.Fatalf("getTranscodeEncoding(%q) error = %v", tt.to, err)
+			}
+			output := &bytes.Buffer{}
+			err = transcode(strings.NewReader(tt.input), output, from, to, tt.ignoreGarbage, tt.wrapAfter)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("transcode() error = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("transcode() error = %v, want %q", err, tt.wantErr)
+			}
+			if output.String() != tt.wantOutput {
+				t.Errorf("transcode() output = %q, want %q", output, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func Test_getTranscodeEncoding(t *testing.T) {
+	if _, err := getTranscodeEncoding("base85", false); err == nil {
+		t.Errorf("getTranscodeEncoding(%q) error = nil", "base85")
+	}
+	if _, ok := getBasencEncoding("base58"); ok {
+		t.Errorf("base58 must not be a basenc encoding")
+	}
+}
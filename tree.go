@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zemanlx/base64/xbase"
+)
+
+// manifestVersion is version of manifest written by --recursive
+const manifestVersion = 1
+
+// manifest describe directory tree, content of files is base64 encoded
+type manifest struct {
+	Version int             `json:"version"`
+	Files   []manifestEntry `json:"files"`
+}
+
+// manifestEntry is file or directory of manifest with path relative to the
+// root of the tree, always separated by slash
+type manifestEntry struct {
+	Path    string `json:"path"`
+	Mode    string `json:"mode"` // octal permissions, e.g. 0644
+	Dir     bool   `json:"dir,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// runRecursive encode directory opts.recursive to manifest on standard
+// output, or restore it from manifest in fileName or standard input
+func runRecursive(opts *codecOptions, fileName string) error {
+	switch {
+	case opts.jsonPath != "" || opts.lines || opts.hexdump:
+		return fmt.Errorf("--recursive cannot be used with --json-path, --lines or --hexdump")
+	case opts.compression != "" || opts.digest != "" || opts.expectDigest != "" || opts.encrypt || opts.decrypt:
+		return fmt.Errorf("--recursive cannot be used with compression, digest or encryption")
+	case opts.from != "" || opts.to != "" || len(opts.strings) > 0 || opts.archive != "" || opts.extract != "":
+		return fmt.Errorf("--recursive cannot be used with --from, --to, --string, --archive or --extract")
+	}
+	encoding := getEncoding(opts.noPadding, opts.url)
+
+	if opts.decode {
+		file, err := openInput(opts, fileName)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err = restoreTree(file, opts.recursive, encoding); err != nil {
+			return fmt.Errorf("decode pipeline error: %v", err)
+		}
+		return nil
+	}
+
+	if fileName != "" {
+		return fmt.Errorf("--recursive encodes DIR, FILE %s cannot be given", fileName)
+	}
+	stdout, flush, err := openOutput(opts)
+	if err != nil {
+		return err
+	}
+	if err = encodeTree(opts.recursive, stdout, encoding); err != nil {
+		return fmt.Errorf("encode pipeline error: %v", err)
+	}
+	return flush()
+}
+
+// encodeTree write manifest of files and directories under root to output,
+// one entry per line so manifests can be compared by diff
+func encodeTree(root string, output io.Writer, encoding *base64.Encoding) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", root)
+	}
+
+	if _, err = fmt.Fprintf(output, "{\"version\":%d,\"files\":[", manifestVersion); err != nil {
+		return err
+	}
+	separator := "\n"
+	err = filepath.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return err
+		}
+		entry := manifestEntry{
+			Path: filepath.ToSlash(rel),
+			Mode: fmt.Sprintf("%04o", info.Mode().Perm()),
+			Dir:  info.IsDir(),
+		}
+		if !info.IsDir() {
+			if !info.Mode().IsRegular() {
+				return fmt.Errorf("%s: unsupported file type %v", name, info.Mode()&os.ModeType)
+			}
+			if entry.Content, err = encodeFile(name, encoding); err != nil {
+				return err
+			}
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(output, "%s%s", separator, line); err != nil {
+			return err
+		}
+		separator = ",\n"
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(output, "\n]}\n")
+	return err
+}
+
+func encodeFile(name string, encoding *base64.Encoding) (string, error) {
+	file, err := os.Open(name) // #nosec G304 -- files of the tree given by user
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	content := &strings.Builder{}
+	if err = xbase.Encode64(file, content, encoding, 0); err != nil {
+		return "", fmt.Errorf("%s: %v", name, err)
+	}
+	return content.String(), nil
+}
+
+// restoreTree create files and directories of manifest read from input
+// under root, entries escaping root directly or through symlink are refused
+func restoreTree(input io.Reader, root string, encoding *base64.Encoding) error {
+	m := manifest{}
+	if err := json.NewDecoder(input).Decode(&m); err != nil {
+		return fmt.Errorf("invalid manifest: %v", err)
+	}
+	if m.Version != manifestVersion {
+		return fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return err
+	}
+
+	// permissions of directories are set at the end, so read-only directories
+	// can be filled first
+	var dirs []manifestEntry
+	for _, entry := range m.Files {
+		name, err := safePath(root, entry.Path)
+		if err != nil {
+			return err
+		}
+		mode, err := strconv.ParseUint(entry.Mode, 8, 32)
+		if err != nil || os.FileMode(mode)&^os.ModePerm != 0 {
+			return fmt.Errorf("%s: invalid mode %q", entry.Path, entry.Mode)
+		}
+		if entry.Dir {
+			if err = os.MkdirAll(name, 0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, entry)
+			continue
+		}
+		if err = restoreFile(name, os.FileMode(mode), entry.Content, encoding); err != nil {
+			return fmt.Errorf("%s: %v", entry.Path, err)
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		mode, _ := strconv.ParseUint(dirs[i].Mode, 8, 32) // validated above
+		name, _ := safePath(root, dirs[i].Path)
+		if err := os.Chmod(name, os.FileMode(mode)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreFile(name string, mode os.FileMode, content string, encoding *base64.Encoding) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600) // #nosec G304 -- checked by safePath
+	if err != nil {
+		return err
+	}
+	if err = xbase.Decode64(strings.NewReader(content), file, encoding, false); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(name, mode)
+}
+
+// safePath return name of slash separated rel under root, it fails when rel
+// is absolute, leaves root with .. or when any existing part of it is symlink
+func safePath(root, rel string) (string, error) {
+	cleaned := path.Clean(rel)
+	switch {
+	case rel == "" || path.IsAbs(rel) || strings.Contains(rel, "\\") || filepath.VolumeName(rel) != "":
+		return "", fmt.Errorf("unsafe path %q", rel)
+	case cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../"):
+		return "", fmt.Errorf("unsafe path %q escapes %s", rel, root)
+	}
+
+	name := root
+	for _, part := range strings.Split(cleaned, "/") {
+		name = filepath.Join(name, part)
+		info, err := os.Lstat(name)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("unsafe path %q goes through symlink %s", rel, name)
+		}
+	}
+	return filepath.Join(root, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_encodeTree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tree")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	source := filepath.Join(dir, "source")
+	files := map[string]string{"a/x.txt": "hi\n", "bin": "\x00\x01"}
+	for name, content := range files {
+		name = filepath.Join(source, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatalf("cannot create directory: %v", err)
+		}
+		if err = ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("cannot write file: %v", err)
+		}
+	}
+	if err = os.Chmod(filepath.Join(source, "bin"), 0755); err != nil {
+		t.Fatalf("cannot change mode: %v", err)
+	}
+	if err = os.Mkdir(filepath.Join(source, "empty"), 0700); err != nil {
+		t.Fatalf("cannot create directory: %v", err)
+	}
+
+	output := &bytes.Buffer{}
+	if err = encodeTree(source, output, base64.StdEncoding); err != nil {
+		t.Fatalf("encodeTree() error = %v", err)
+	}
+	want := `{"version":1,"files":[
+{"path":"a","mode":"0755","dir":true},
+{"path":"a/x.txt","mode":"0644","content":"aGkK"},
+{"path":"bin","mode":"0755","content":"AAE="},
+{"path":"empty","mode":"0700","dir":true}
+]}
+`
+	if diff := cmp.Diff(want, output.String()); diff != "" {
+		t.Errorf("encodeTree() mismatch (-want +got):\n%s", diff)
+	}
+
+	target := filepath.Join(dir, "target")
+	if err = restoreTree(output, target, base64.StdEncoding); err != nil {
+		t.Fatalf("restoreTree() error = %v", err)
+	}
+	restored := &bytes.Buffer{}
+	if err = encodeTree(target, restored, base64.StdEncoding); err != nil {
+		t.Fatalf("encodeTree() of restored tree error = %v", err)
+	}
+	if diff := cmp.Diff(want, restored.String()); diff != "" {
+		t.Errorf("restored tree mismatch (-want +got):\n%s", diff)
+	}
+
+	if err = encodeTree(filepath.Join(source, "bin"), ioutil.Discard, base64.StdEncoding); err == nil {
+		t.Errorf("encodeTree() of file error = nil")
+	}
+}
+
+func Test_restoreTree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tree")
+	if err != nil {
+		t.Fatalf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+	if err = os.Symlink(os.TempDir(), filepath.Join(dir, "link")); err != nil {
+		t.Fatalf("cannot create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{"parent directory", `{"version":1,"files":[{"path":"../evil","mode":"0644"}]}`, `unsafe path "../evil" escapes`},
+		{"parent inside path", `{"version":1,"files":[{"path":"a/../../evil","mode":"0644"}]}`, "escapes"},
+		{"absolute path", `{"version":1,"files":[{"path":"/etc/evil","mode":"0644"}]}`, `unsafe path "/etc/evil"`},
+		{"root itself", `{"version":1,"files":[{"path":".","mode":"0755","dir":true}]}`, "unsafe path"},
+		{"through symlink", `{"version":1,"files":[{"path":"link/evil","mode":"0644"}]}`, "goes through symlink"},
+		{"invalid mode", `{"version":1,"files":[{"path":"a","mode":"4755"}]}`, `invalid mode "4755"`},
+		{"invalid content", `{"version":1,"files":[{"path":"a","mode":"0644","content":"!"}]}`, "a: "},
+		{"unknown version", `{"version":2,"files":[]}`, "unsupported manifest version 2"},
+		{"not manifest", `aGk=`, "invalid manifest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := restoreTree(strings.NewReader(tt.manifest), dir, base64.StdEncoding)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("restoreTree() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err = os.Stat(filepath.Join(os.TempDir(), "evil")); err == nil {
+		t.Errorf("file outside of root was created")
+	}
+}
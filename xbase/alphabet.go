@@ -137,3 +137,37 @@ var base64url = alphabet{
 	'_': true,
 	'=': true,
 }
+
+// makeAlphabet return alphabet containing chars
+func makeAlphabet(chars string) (a alphabet) {
+	for i := 0; i < len(chars); i++ {
+		a[chars[i]] = true
+	}
+	return a
+}
+
+var (
+	base32std = makeAlphabet("ABCDEFGHIJKLMNOPQRSTUVWXYZ234567=")
+	base32hex = makeAlphabet("0123456789ABCDEFGHIJKLMNOPQRSTUV=")
+	base16    = makeAlphabet("0123456789ABCDEFabcdef")
+	base2     = makeAlphabet("01")
+	z85       = makeAlphabet(z85Chars)
+	base58    = makeAlphabet(base58Chars)
+)
+
+// noNewlines contains every byte except newlines, garboReader with it drops
+// only newlines for decoders which do not ignore them
+var noNewlines = func() (a alphabet) {
+	for i := range a {
+		a[i] = i != '\n' && i != '\r'
+	}
+	return a
+}()
+
+// noWhitespace contains every byte except ASCII whitespace
+var noWhitespace = func() (a alphabet) {
+	for i := range a {
+		a[i] = i != ' ' && i != '\t' && i != '\n' && i != '\v' && i != '\f' && i != '\r'
+	}
+	return a
+}()
@@ -0,0 +1,48 @@
+package xbase
+
+import (
+	"encoding/hex"
+	"io"
+)
+
+// Encode16 read stream from input and encode it to upper case base16 (hex)
+// with optional wrapping
+func Encode16(input io.Reader, output io.Writer, wrapAfter uint) error {
+	return encode(input, output, func(w io.Writer) io.WriteCloser {
+		return &base16Encoder{w: w}
+	}, wrapAfter)
+}
+
+// Decode16 read base16 stream in upper or lower case from input and decode
+// it to output with optional garbage ignoring
+func Decode16(input io.Reader, output io.Writer, ignoreGarbage bool) error {
+	alphabet := noNewlines
+	if ignoreGarbage {
+		alphabet = base16
+	}
+	return decode(input, output, alphabet, true, hex.NewDecoder)
+}
+
+const base16Chars = "0123456789ABCDEF"
+
+// base16Encoder encode to upper case hex as RFC 4648 base16 does,
+// hex.NewEncoder produces lower case
+type base16Encoder struct {
+	w io.Writer
+}
+
+func (e *base16Encoder) Write(p []byte) (n int, err error) {
+	b := make([]byte, 2*len(p))
+	for i, v := range p {
+		b[2*i] = base16Chars[v>>4]
+		b[2*i+1] = base16Chars[v&0x0f]
+	}
+	if _, err = e.w.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (e *base16Encoder) Close() error {
+	return nil
+}
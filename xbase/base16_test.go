@@ -0,0 +1,65 @@
+package xbase
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Encode16(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wrapAfter  uint
+		wantOutput string
+	}{
+		{"empty input", "", 76, ""},
+		{"upper case output", "hello world!", 76, "68656C6C6F20776F726C6421\n"},
+		{"wrap after 10", "hello world!", 10, "68656C6C6F\n20776F726C\n6421\n"},
+		{"no wrap", "\x00\xff", 0, "00FF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			if err := Encode16(strings.NewReader(tt.input), output, tt.wrapAfter); err != nil {
+				t.Errorf("Encode16() error = %v", err)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("Encode16() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Decode16(t *testing.T) {
+	type args struct {
+		input         string
+		ignoreGarbage bool
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantOutput string
+		wantErr    bool
+	}{
+		{"empty input", args{"", false}, "", false},
+		{"upper case with newlines", args{"68656C6C6F\r\n20776F726C\n6421\n", false}, "hello world!", false},
+		{"lower case", args{"68656c6c6f", false}, "hello", false},
+		{"garbage fails", args{"68 65", false}, "", true},
+		{"garbage is ignored", args{"68 65~6C", true}, "hel", false},
+		{"odd length fails", args{"686", false}, "h", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			if err := Decode16(strings.NewReader(tt.args.input), output, tt.args.ignoreGarbage); (err != nil) != tt.wantErr {
+				t.Errorf("Decode16() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("Decode16() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package xbase
+
+import (
+	"fmt"
+	"io"
+)
+
+// EncodeBase2 read stream from input and encode it to string of 0 and 1 with
+// optional wrapping, bits of every byte go from the most significant one or
+// with lsbf from the least significant one
+func EncodeBase2(input io.Reader, output io.Writer, lsbf bool, wrapAfter uint) error {
+	return encode(input, output, func(w io.Writer) io.WriteCloser {
+		return &base2Encoder{lsbf: lsbf, w: w}
+	}, wrapAfter)
+}
+
+// DecodeBase2 read string of 0 and 1 from input and decode it to output,
+// whitespace is ignored and with ignoreGarbage any other character too
+func DecodeBase2(input io.Reader, output io.Writer, lsbf bool, ignoreGarbage bool) error {
+	alphabet := noWhitespace
+	if ignoreGarbage {
+		alphabet = base2
+	}
+	return decode(input, output, alphabet, true, func(r io.Reader) io.Reader {
+		return &base2Decoder{lsbf: lsbf, r: r}
+	})
+}
+
+type base2Encoder struct {
+	lsbf bool
+
+	w io.Writer
+}
+
+func (e *base2Encoder) Write(p []byte) (n int, err error) {
+	b := make([]byte, 8*len(p))
+	for i, v := range p {
+		for bit := 0; bit < 8; bit++ {
+			shift := uint(7 - bit)
+			if e.lsbf {
+				shift = uint(bit)
+			}
+			b[8*i+bit] = '0' + (v>>shift)&1
+		}
+	}
+	if _, err = e.w.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (e *base2Encoder) Close() error {
+	return nil
+}
+
+type base2Decoder struct {
+	lsbf   bool
+	err    error
+	offset int64 // number of characters already decoded
+	in     [8 * 1024]byte
+	nin    int
+
+	r io.Reader
+}
+
+func (d *base2Decoder) Read(p []byte) (n int, err error) {
+	if d.err != nil && d.err != io.EOF {
+		return 0, d.err
+	}
+
+	for d.nin < 8 && d.err == nil {
+		var nr int
+		nr, d.err = d.r.Read(d.in[d.nin:])
+		d.nin += nr
+	}
+	if d.nin < 8 {
+		if d.err == io.EOF && d.nin > 0 {
+			d.err = fmt.Errorf("invalid input (length must be multiple of 8 characters)")
+		}
+		return 0, d.err
+	}
+
+	bytes := d.nin / 8
+	if bytes > len(p) {
+		bytes = len(p)
+	}
+	for n = 0; n < bytes; n++ {
+		var value byte
+		for bit, char := range d.in[8*n : 8*n+8] {
+			if char != '0' && char != '1' {
+				d.err = fmt.Errorf("illegal base2 data at input byte %d", d.offset+int64(8*n+bit))
+				return n, nil
+			}
+			shift := uint(7 - bit)
+			if d.lsbf {
+				shift = uint(bit)
+			}
+			value |= (char - '0') << shift
+		}
+		p[n] = value
+	}
+	d.offset += int64(8 * n)
+	d.nin = copy(d.in[:], d.in[8*n:d.nin])
+	return n, nil
+}
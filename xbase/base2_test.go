@@ -0,0 +1,72 @@
+package xbase
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_EncodeBase2(t *testing.T) {
+	type args struct {
+		input     string
+		lsbf      bool
+		wrapAfter uint
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantOutput string
+	}{
+		{"empty input", args{"", false, 76}, ""},
+		{"most significant bit first", args{"Ab", false, 76}, "0100000101100010\n"},
+		{"least significant bit first", args{"Ab", true, 76}, "1000001001000110\n"},
+		{"wrap after 5", args{"Ab", false, 5}, "01000\n00101\n10001\n0\n"},
+		{"no wrap", args{"\x00\xff", false, 0}, "0000000011111111"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			if err := EncodeBase2(strings.NewReader(tt.args.input), output, tt.args.lsbf, tt.args.wrapAfter); err != nil {
+				t.Errorf("EncodeBase2() error = %v", err)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("EncodeBase2() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_DecodeBase2(t *testing.T) {
+	type args struct {
+		input         string
+		lsbf          bool
+		ignoreGarbage bool
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantOutput string
+		wantErr    bool
+	}{
+		{"empty input", args{"", false, false}, "", false},
+		{"most significant bit first", args{"0100000101100010\n", false, false}, "Ab", false},
+		{"least significant bit first", args{"1000001001000110", true, false}, "Ab", false},
+		{"whitespace is ignored", args{"0100 0001\t0110\r\n0010\n", false, false}, "Ab", false},
+		{"garbage fails", args{"01000001x0110001", false, false}, "A", true},
+		{"garbage is ignored", args{"01000001x01100010", false, true}, "Ab", false},
+		{"length not multiple of 8", args{"010000010", false, false}, "A", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			if err := DecodeBase2(strings.NewReader(tt.args.input), output, tt.args.lsbf, tt.args.ignoreGarbage); (err != nil) != tt.wantErr {
+				t.Errorf("DecodeBase2() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("DecodeBase2() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package xbase
+
+import (
+	"encoding/base32"
+	"fmt"
+	"io"
+)
+
+// Encode32 read stream from input and encode it to base32 with optional wrapping
+func Encode32(input io.Reader, output io.Writer, encoding *base32.Encoding, wrapAfter uint) error {
+	return encode(input, output, func(w io.Writer) io.WriteCloser {
+		return base32.NewEncoder(encoding, w)
+	}, wrapAfter)
+}
+
+// Decode32 read base32 stream from input and decode it to output with
+// optional garbage ignoring
+func Decode32(input io.Reader, output io.Writer, encoding *base32.Encoding, ignoreGarbage bool) error {
+	var alphabet alphabet
+	switch encoding {
+	case base32.StdEncoding:
+		alphabet = base32std
+	case base32.HexEncoding:
+		alphabet = base32hex
+	default:
+		return fmt.Errorf("encoding is not supported")
+	}
+
+	return decode(input, output, alphabet, ignoreGarbage, func(r io.Reader) io.Reader {
+		return base32.NewDecoder(encoding, r)
+	})
+}
@@ -0,0 +1,76 @@
+package xbase
+
+import (
+	"bytes"
+	"encoding/base32"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Encode32(t *testing.T) {
+	type args struct {
+		input     string
+		encoding  *base32.Encoding
+		wrapAfter uint
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantOutput string
+		wantErr    bool
+	}{
+		{"empty input", args{"", base32.StdEncoding, 76}, "", false},
+		{"standard alphabet with padding", args{"hello world!", base32.StdEncoding, 76}, "NBSWY3DPEB3W64TMMQQQ====\n", false},
+		{"extended hex alphabet with padding", args{"hello world!", base32.HexEncoding, 76}, "D1IMOR3F41RMUSJCCGGG====\n", false},
+		{"wrap after 8", args{"hello world!", base32.StdEncoding, 8}, "NBSWY3DP\nEB3W64TM\nMQQQ====\n", false},
+		{"no wrap", args{"hello world!", base32.StdEncoding, 0}, "NBSWY3DPEB3W64TMMQQQ====", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			if err := Encode32(strings.NewReader(tt.args.input), output, tt.args.encoding, tt.args.wrapAfter); (err != nil) != tt.wantErr {
+				t.Errorf("Encode32() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("Encode32() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Decode32(t *testing.T) {
+	type args struct {
+		input         string
+		encoding      *base32.Encoding
+		ignoreGarbage bool
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantOutput string
+		wantErr    bool
+	}{
+		{"empty input", args{"", base32.StdEncoding, false}, "", false},
+		{"standard alphabet with newlines", args{"NBSWY3DP\nEB3W64TM\nMQQQ====\n", base32.StdEncoding, false}, "hello world!", false},
+		{"extended hex alphabet", args{"D1IMOR3F41RMUSJCCGGG====", base32.HexEncoding, false}, "hello world!", false},
+		{"garbage fails", args{"NBSWY3DP$EB3W64TMMQQQ====", base32.StdEncoding, false}, "", true},
+		{"garbage is ignored", args{"NBSWY3DP$EB3W64TM~MQQQ====", base32.StdEncoding, true}, "hello world!", false},
+		{"lower case is garbage", args{"nbswyxdpNBSWY3DP", base32.StdEncoding, true}, "hello", false},
+		{"unsupported encoding", args{"NBSWY3DP", base32.StdEncoding.WithPadding(base32.NoPadding), false}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			err := Decode32(strings.NewReader(tt.args.input), output, tt.args.encoding, tt.args.ignoreGarbage)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode32() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); !tt.wantErr && diff != "" {
+				t.Errorf("Decode32() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
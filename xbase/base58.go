@@ -0,0 +1,140 @@
+package xbase
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// base58Chars is the Bitcoin base58 alphabet, it leaves out 0, O, I and l
+const base58Chars = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Values = func() (v [256]byte) {
+	for i := range v {
+		v[i] = 0xff
+	}
+	for i := 0; i < len(base58Chars); i++ {
+		v[base58Chars[i]] = byte(i)
+	}
+	return v
+}()
+
+// EncodeBase58 read stream from input and encode it to base58 with optional
+// wrapping, base58 is not a block encoding so whole input is kept in memory
+func EncodeBase58(input io.Reader, output io.Writer, wrapAfter uint) error {
+	return encode(input, output, func(w io.Writer) io.WriteCloser {
+		return &base58Encoder{w: w}
+	}, wrapAfter)
+}
+
+// DecodeBase58 read base58 stream from input and decode it to output with
+// optional garbage ignoring, whole input is kept in memory
+func DecodeBase58(input io.Reader, output io.Writer, ignoreGarbage bool) error {
+	alphabet := noNewlines
+	if ignoreGarbage {
+		alphabet = base58
+	}
+	return decode(input, output, alphabet, true, func(r io.Reader) io.Reader {
+		return &base58Decoder{r: r}
+	})
+}
+
+// base58Encoder collect input and encode it on Close
+type base58Encoder struct {
+	data bytes.Buffer
+
+	w io.Writer
+}
+
+func (e *base58Encoder) Write(p []byte) (n int, err error) {
+	return e.data.Write(p)
+}
+
+func (e *base58Encoder) Close() error {
+	_, err := e.w.Write(appendBase58(nil, e.data.Bytes()))
+	return err
+}
+
+// appendBase58 append base58 encoding of data to b, every leading zero byte
+// is encoded as 1
+func appendBase58(b, data []byte) []byte {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	// log(256) / log(58) is less than 1.37
+	digits := make([]byte, (len(data)-zeros)*137/100+1)
+	high := len(digits) // digits[high:] are used
+	for _, v := range data[zeros:] {
+		carry := int(v)
+		i := len(digits) - 1
+		for ; i >= high || carry != 0; i-- {
+			carry += 256 * int(digits[i])
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		high = i + 1
+	}
+	for i := 0; i < zeros; i++ {
+		b = append(b, base58Chars[0])
+	}
+	for _, digit := range digits[high:] {
+		b = append(b, base58Chars[digit])
+	}
+	return b
+}
+
+// base58Decoder read whole input and decode it on the first Read
+type base58Decoder struct {
+	err  error
+	pend []byte // decoded bytes not returned yet
+	done bool
+
+	r io.Reader
+}
+
+func (d *base58Decoder) Read(p []byte) (n int, err error) {
+	if !d.done {
+		d.done = true
+		var input []byte
+		if input, d.err = ioutil.ReadAll(d.r); d.err == nil {
+			d.pend, d.err = decodeBase58(input)
+		}
+	}
+	if len(d.pend) > 0 {
+		n = copy(p, d.pend)
+		d.pend = d.pend[n:]
+		return n, nil
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return 0, io.EOF
+}
+
+// decodeBase58 decode base58 characters, every leading 1 is decoded as zero
+// byte
+func decodeBase58(input []byte) ([]byte, error) {
+	zeros := 0
+	for zeros < len(input) && input[zeros] == base58Chars[0] {
+		zeros++
+	}
+	// log(58) / log(256) is less than 0.733
+	decoded := make([]byte, (len(input)-zeros)*733/1000+1)
+	high := len(decoded)
+	for offset, char := range input[zeros:] {
+		carry := int(base58Values[char])
+		if carry == 0xff {
+			return nil, fmt.Errorf("illegal base58 data at input byte %d", zeros+offset)
+		}
+		i := len(decoded) - 1
+		for ; i >= high || carry != 0; i-- {
+			carry += 58 * int(decoded[i])
+			decoded[i] = byte(carry)
+			carry >>= 8
+		}
+		high = i + 1
+	}
+	return append(make([]byte, zeros), decoded[high:]...), nil
+}
@@ -0,0 +1,84 @@
+package xbase
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_EncodeBase58(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wrapAfter  uint
+		wantOutput string
+	}{
+		{"empty input", "", 76, ""},
+		{"single byte", "a", 0, "2g"},
+		{"text", "hello world", 76, "StV1DL6CwTryKyV\n"},
+		{"leading zeros", "\x00\x00\x28\x7f\xb4\xcd", 0, "11233QC4"},
+		{"only zeros", "\x00\x00\x00", 0, "111"},
+		{"wrap after 5", "hello world", 5, "StV1D\nL6CwT\nryKyV\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			if err := EncodeBase58(strings.NewReader(tt.input), output, tt.wrapAfter); err != nil {
+				t.Errorf("EncodeBase58() error = %v", err)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("EncodeBase58() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_DecodeBase58(t *testing.T) {
+	type args struct {
+		input         string
+		ignoreGarbage bool
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantOutput string
+		wantErr    bool
+	}{
+		{"empty input", args{"", false}, "", false},
+		{"text", args{"StV1DL6CwTryKyV", false}, "hello world", false},
+		{"leading zeros", args{"11233QC4", false}, "\x00\x00\x28\x7f\xb4\xcd", false},
+		{"newlines are ignored", args{"StV1D\nL6CwT\r\nryKyV\n", false}, "hello world", false},
+		{"character outside alphabet fails", args{"StV1D0L6CwTryKyV", false}, "", true},
+		{"garbage is ignored", args{"StV1D-L6CwT ryKyV", true}, "hello world", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			if err := DecodeBase58(strings.NewReader(tt.args.input), output, tt.args.ignoreGarbage); (err != nil) != tt.wantErr {
+				t.Errorf("DecodeBase58() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("DecodeBase58() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_base58RoundTrip(t *testing.T) {
+	for size := 0; size < 300; size += 7 {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i * 31 >> uint(i%3))
+		}
+		encoded := appendBase58(nil, data)
+		decoded, err := decodeBase58(encoded)
+		if err != nil {
+			t.Fatalf("decodeBase58(%q) error = %v", encoded, err)
+		}
+		if !bytes.Equal(decoded, data) {
+			t.Errorf("decodeBase58(appendBase58(%x)) = %x", data, decoded)
+		}
+	}
+}
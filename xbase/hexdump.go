@@ -0,0 +1,89 @@
+package xbase
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+const hexdumpWidth = 16 // bytes per line
+
+// NewHexdumper return writer which writes canonical hex+ASCII dump of data
+// (as hexdump -C does) to w, Close must be called to flush the last line
+func NewHexdumper(w io.Writer) io.WriteCloser {
+	return &hexdumper{w: w}
+}
+
+type hexdumper struct {
+	offset   int64
+	line     [hexdumpWidth]byte
+	nline    int
+	prev     [hexdumpWidth]byte
+	hasPrev  bool
+	squeezed bool // identical lines are replaced by single *
+
+	w io.Writer
+}
+
+func (h *hexdumper) Write(p []byte) (n int, err error) {
+	b := &bytes.Buffer{}
+	for _, v := range p {
+		h.line[h.nline] = v
+		if h.nline++; h.nline < hexdumpWidth {
+			continue
+		}
+		switch {
+		case h.hasPrev && h.line == h.prev && h.squeezed:
+		case h.hasPrev && h.line == h.prev:
+			b.WriteString("*\n")
+			h.squeezed = true
+		default:
+			writeHexdumpLine(b, h.offset, h.line[:])
+			h.squeezed = false
+		}
+		h.prev, h.hasPrev = h.line, true
+		h.offset += hexdumpWidth
+		h.nline = 0
+	}
+	if _, err = h.w.Write(b.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close write the last incomplete line and the final offset
+func (h *hexdumper) Close() error {
+	b := &bytes.Buffer{}
+	if h.nline > 0 {
+		writeHexdumpLine(b, h.offset, h.line[:h.nline])
+		h.offset += int64(h.nline)
+		h.nline = 0
+	}
+	if h.offset > 0 {
+		fmt.Fprintf(b, "%08x\n", h.offset)
+	}
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+func writeHexdumpLine(b *bytes.Buffer, offset int64, line []byte) {
+	fmt.Fprintf(b, "%08x  ", offset)
+	for i := 0; i < hexdumpWidth; i++ {
+		if i == hexdumpWidth/2 {
+			b.WriteByte(' ')
+		}
+		if i < len(line) {
+			fmt.Fprintf(b, "%02x ", line[i])
+		} else {
+			b.WriteString("   ")
+		}
+	}
+	b.WriteString(" |")
+	for _, v := range line {
+		if v < 0x20 || v > 0x7e {
+			v = '.'
+		}
+		b.WriteByte(v)
+	}
+	b.WriteString("|\n")
+}
@@ -0,0 +1,62 @@
+package xbase
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_hexdumper(t *testing.T) {
+	tests := []struct {
+		name       string
+		writes     []string
+		wantOutput string
+	}{
+		{"empty input", nil, ""},
+		{
+			"partial line",
+			[]string{"hello world\n"},
+			"00000000  68 65 6c 6c 6f 20 77 6f  72 6c 64 0a              |hello world.|\n0000000c\n",
+		},
+		{
+			"full line split across writes",
+			[]string{"0123456", "789abcdef"},
+			"00000000  30 31 32 33 34 35 36 37  38 39 61 62 63 64 65 66  |0123456789abcdef|\n00000010\n",
+		},
+		{
+			"identical lines are squeezed",
+			[]string{strings.Repeat("\x00", 100)},
+			"00000000  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|\n" +
+				"*\n" +
+				"00000060  00 00 00 00                                       |....|\n" +
+				"00000064\n",
+		},
+		{
+			"squeezing stops at different line",
+			[]string{strings.Repeat("a", 48) + strings.Repeat("b", 16)},
+			"00000000  61 61 61 61 61 61 61 61  61 61 61 61 61 61 61 61  |aaaaaaaaaaaaaaaa|\n" +
+				"*\n" +
+				"00000030  62 62 62 62 62 62 62 62  62 62 62 62 62 62 62 62  |bbbbbbbbbbbbbbbb|\n" +
+				"00000040\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			dumper := NewHexdumper(output)
+			for _, w := range tt.writes {
+				if n, err := dumper.Write([]byte(w)); err != nil || n != len(w) {
+					t.Fatalf("hexdumper.Write() = %v, %v, want %v", n, err, len(w))
+				}
+			}
+			if err := dumper.Close(); err != nil {
+				t.Fatalf("hexdumper.Close() error = %v", err)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("hexdumper mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package xbase
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	maxGarbagePositions = 10  // garbage positions kept in Report
+	maxHead             = 512 // decoded bytes kept in Report
+)
+
+// Report describes base64 input analysed by Inspect64 without trusting it
+type Report struct {
+	Size             int64         // bytes of input
+	DataChars        int64         // characters of either base64 alphabet excluding padding
+	StdChars         int64         // characters + and / of standard alphabet
+	URLChars         int64         // characters - and _ of URL alphabet
+	Padding          int           // padding characters at the end of data
+	InnerPadding     int64         // padding characters followed by more data
+	Segments         int           // independently padded segments
+	Lines            int64         // number of lines
+	LineLengths      map[int]int64 // number of lines by length without line ending
+	LastLineLength   int           // length of the last line without line ending
+	CRLF             int64         // lines ending with \r\n
+	Garbage          int64         // characters dropped by --ignore-garbage except newlines
+	GarbagePositions []int64       // offsets of first garbage characters
+	Truncated        bool          // some segment ends with a single character
+	NonCanonical     bool          // unused trailing bits of some segment are not zero
+	DecodedSize      int64         // bytes of decoded data
+	Head             []byte        // first decoded bytes
+}
+
+// base64Values map characters of both base64 alphabets to their values
+var base64Values = func() (v [256]int8) {
+	for i := range v {
+		v[i] = -1
+	}
+	const std = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	for i := 0; i < len(std); i++ {
+		v[std[i]] = int8(i)
+	}
+	v['-'], v['_'] = 62, 63
+	return v
+}()
+
+// Inspect64 read base64 from input and analyse its alphabet, padding,
+// wrapping and garbage, data are decoded with both alphabets at once and
+// padding in the middle starts a new segment
+func Inspect64(input io.Reader) (*Report, error) {
+	var (
+		r       = bufio.NewReader(input)
+		report  = &Report{LineLengths: map[int]int64{}}
+		line    int    // length of current line
+		quantum int    // characters in current quantum
+		bits    uint32 // accumulated bits of current quantum
+		padRun  int    // padding characters since the last data character
+		prev    byte
+	)
+
+	emit := func(b byte) {
+		if len(report.Head) < maxHead {
+			report.Head = append(report.Head, b)
+		}
+		report.DecodedSize++
+	}
+	// finish partial quantum at the end of segment
+	finish := func() {
+		switch quantum {
+		case 1:
+			report.Truncated = true
+		case 2:
+			report.NonCanonical = report.NonCanonical || bits&0xf != 0
+			emit(byte(bits >> 4))
+		case 3:
+			report.NonCanonical = report.NonCanonical || bits&0x3 != 0
+			emit(byte(bits >> 10))
+			emit(byte(bits >> 2))
+		}
+		quantum, bits = 0, 0
+	}
+
+	for ; ; report.Size++ {
+		char, err := r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read from input: %v", err)
+		}
+
+		switch value := base64Values[char]; {
+		case char == '\n':
+			if prev == '\r' {
+				report.CRLF++
+			}
+			report.Lines++
+			report.LineLengths[line]++
+			report.LastLineLength = line
+			line = 0
+		case char == '\r':
+		case char == '=':
+			line++
+			if padRun == 0 && report.DataChars > 0 {
+				finish()
+				report.Segments++
+			}
+			padRun++
+		case value >= 0:
+			line++
+			if padRun > 0 {
+				report.InnerPadding += int64(padRun)
+				padRun = 0
+			}
+			report.DataChars++
+			switch char {
+			case '+', '/':
+				report.StdChars++
+			case '-', '_':
+				report.URLChars++
+			}
+			bits = bits<<6 | uint32(value)
+			if quantum++; quantum == 4 {
+				emit(byte(bits >> 16))
+				emit(byte(bits >> 8))
+				emit(byte(bits))
+				quantum, bits = 0, 0
+			}
+		default:
+			line++
+			report.Garbage++
+			if len(report.GarbagePositions) < maxGarbagePositions {
+				report.GarbagePositions = append(report.GarbagePositions, report.Size)
+			}
+		}
+		prev = char
+	}
+
+	if line > 0 {
+		report.Lines++
+		report.LineLengths[line]++
+		report.LastLineLength = line
+	}
+	if padRun == 0 && report.DataChars > 0 {
+		finish()
+		report.Segments++
+	}
+	report.Padding = padRun
+	return report, nil
+}
+
+// Alphabets describe base64 alphabets used in input
+func (r *Report) Alphabets() string {
+	switch {
+	case r.StdChars > 0 && r.URLChars > 0:
+		return "mixed standard (+/) and URL (-_)"
+	case r.StdChars > 0:
+		return "standard (+/)"
+	case r.URLChars > 0:
+		return "URL (-_)"
+	}
+	return "standard or URL (no +/-_ characters)"
+}
+
+// PaddingStyle describe padding used in input
+func (r *Report) PaddingStyle() string {
+	switch {
+	case r.InnerPadding > 0:
+		return fmt.Sprintf("%d independently padded segments (use --concatenated)", r.Segments)
+	case r.Padding > 0:
+		return fmt.Sprintf("padded (%d)", r.Padding)
+	case r.DataChars%4 == 0:
+		return "none needed"
+	}
+	return "unpadded (use --no-padding)"
+}
+
+// Wrap return line length used for wrapping and whether all lines except
+// the last, shorter one have this length, 0 means no wrapping
+func (r *Report) Wrap() (cols int, regular bool) {
+	if r.Lines <= 1 {
+		return 0, true
+	}
+	lengths := make([]int, 0, len(r.LineLengths))
+	for length := range r.LineLengths {
+		lengths = append(lengths, length)
+	}
+	sort.Ints(lengths)
+	cols = lengths[len(lengths)-1]
+	switch {
+	case len(lengths) == 1:
+		return cols, true
+	case len(lengths) == 2 && r.LineLengths[lengths[0]] == 1 && r.LastLineLength == lengths[0]:
+		return cols, true
+	}
+	return cols, false
+}
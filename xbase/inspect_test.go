@@ -0,0 +1,89 @@
+package xbase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Inspect64(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantReport *Report
+	}{
+		{
+			"empty input",
+			"",
+			&Report{LineLengths: map[int]int64{}},
+		},
+		{
+			"wrapped standard alphabet with padding",
+			"bG/Cow==bG/C\nb3c=\n",
+			&Report{
+				Size: 18, DataChars: 13, StdChars: 2, Padding: 1, InnerPadding: 2, Segments: 2,
+				Lines: 2, LineLengths: map[int]int64{12: 1, 4: 1}, LastLineLength: 4,
+				DecodedSize: 9, Head: []byte("lo£lo\xc2ow"),
+			},
+		},
+		{
+			"URL alphabet without padding and CRLF",
+			"bG_Cow\r\n",
+			&Report{
+				Size: 8, DataChars: 6, URLChars: 1, Segments: 1,
+				Lines: 1, LineLengths: map[int]int64{6: 1}, LastLineLength: 6, CRLF: 1,
+				DecodedSize: 4, Head: []byte("lo£"),
+			},
+		},
+		{
+			"garbage, truncated and non canonical segments",
+			"Y$R=*Y\n",
+			&Report{
+				Size: 7, DataChars: 3, Padding: 0, InnerPadding: 1, Segments: 2,
+				Lines: 1, LineLengths: map[int]int64{6: 1}, LastLineLength: 6,
+				Garbage: 2, GarbagePositions: []int64{1, 4},
+				Truncated: true, NonCanonical: true,
+				DecodedSize: 1, Head: []byte("a"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotReport, err := Inspect64(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Inspect64() error = %v", err)
+			}
+			if diff := cmp.Diff(gotReport, tt.wantReport); diff != "" {
+				t.Errorf("Inspect64() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Report_Wrap(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantCols    int
+		wantRegular bool
+	}{
+		{"single line", "YWJj\n", 0, true},
+		{"all lines of the same length", "YWJj\nYWJj\n", 4, true},
+		{"shorter last line", "YWJj\nYWJj\nYQ==\nYQ\n", 4, true},
+		{"shorter line in the middle", "YWJj\nYQ\nYWJj\n", 4, false},
+		{"more line lengths", "YWJjYWJj\nYWJj\nYQ\n", 8, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Inspect64(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Inspect64() error = %v", err)
+			}
+			gotCols, gotRegular := report.Wrap()
+			if gotCols != tt.wantCols || gotRegular != tt.wantRegular {
+				t.Errorf("Report.Wrap() = %v, %v, want %v, %v", gotCols, gotRegular, tt.wantCols, tt.wantRegular)
+			}
+		})
+	}
+}
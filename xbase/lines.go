@@ -0,0 +1,91 @@
+package xbase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// LineError describes a record which could not be processed in line mode
+type LineError struct {
+	Line int // 1-based record number
+	Err  error
+}
+
+func (le *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", le.Line, le.Err)
+}
+
+// EncodeLines64 read records separated by delimiter from input and encode
+// each of them independently to one output record terminated by delimiter
+func EncodeLines64(input io.Reader, output io.Writer, encoding *base64.Encoding, delimiter byte) error {
+	w := bufio.NewWriter(output)
+	err := forEachRecord(input, delimiter, func(line int, record []byte) error {
+		if err := plainEncode(bytes.NewReader(record), w, encoding); err != nil {
+			return &LineError{Line: line, Err: err}
+		}
+		return w.WriteByte(delimiter)
+	})
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// DecodeLines64 read records separated by delimiter from input and decode
+// each of them independently to one output record terminated by delimiter.
+// Records which cannot be decoded are passed to onInvalid as *LineError, the
+// record is skipped when onInvalid returns nil. With nil onInvalid the first
+// invalid record stops decoding.
+func DecodeLines64(input io.Reader, output io.Writer, encoding *base64.Encoding, ignoreGarbage bool, delimiter byte, onInvalid func(*LineError) error) error {
+	if _, err := getAlphabet(encoding); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(output)
+	decoded := &bytes.Buffer{}
+	err := forEachRecord(input, delimiter, func(line int, record []byte) error {
+		decoded.Reset()
+		if err := Decode64(bytes.NewReader(record), decoded, encoding, ignoreGarbage); err != nil {
+			lerr := &LineError{Line: line, Err: err}
+			if onInvalid == nil {
+				return lerr
+			}
+			return onInvalid(lerr)
+		}
+		if _, err := w.Write(decoded.Bytes()); err != nil {
+			return err
+		}
+		return w.WriteByte(delimiter)
+	})
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// forEachRecord call fn for every record in input without its delimiter,
+// last record does not need to be terminated
+func forEachRecord(input io.Reader, delimiter byte, fn func(line int, record []byte) error) error {
+	r := bufio.NewReader(input)
+	for line := 1; ; line++ {
+		record, err := r.ReadBytes(delimiter)
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("cannot read from input: %v", err)
+		}
+		if err == io.EOF && len(record) == 0 {
+			return nil
+		}
+		if record[len(record)-1] == delimiter {
+			record = record[:len(record)-1]
+		}
+		if ferr := fn(line, record); ferr != nil {
+			return ferr
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package xbase
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_EncodeLines64(t *testing.T) {
+	type args struct {
+		input     string
+		encoding  *base64.Encoding
+		delimiter byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantOutput string
+		wantErr    bool
+	}{
+		{"empty input", args{"", base64.StdEncoding, '\n'}, "", false},
+		{"single line without newline", args{"simple", base64.StdEncoding, '\n'}, "c2ltcGxl\n", false},
+		{"lines are encoded independently", args{"a\nb\n", base64.StdEncoding, '\n'}, "YQ==\nYg==\n", false},
+		{"empty line is kept", args{"a\n\nb", base64.StdEncoding, '\n'}, "YQ==\n\nYg==\n", false},
+		{"carriage return is part of the record", args{"a\r\n", base64.StdEncoding, '\n'}, "YQ0=\n", false},
+		{"URL encoding with no padding", args{"lo£\nlo£", base64.RawURLEncoding, '\n'}, "bG_Cow\nbG_Cow\n", false},
+		{"NUL delimited records keep newlines", args{"a\nb\x00c\x00", base64.StdEncoding, 0}, "YQpi\x00Yw==\x00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			if err := EncodeLines64(strings.NewReader(tt.args.input), output, tt.args.encoding, tt.args.delimiter); (err != nil) != tt.wantErr {
+				t.Errorf("EncodeLines64() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("EncodeLines64() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_DecodeLines64(t *testing.T) {
+	type args struct {
+		input         string
+		encoding      *base64.Encoding
+		ignoreGarbage bool
+		delimiter     byte
+		skipInvalid   bool
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantOutput  string
+		wantInvalid []int
+		wantErr     bool
+	}{
+		{"empty input", args{"", base64.StdEncoding, false, '\n', false}, "", nil, false},
+		{"lines are decoded independently", args{"YQ==\nYg==\n", base64.StdEncoding, false, '\n', false}, "a\nb\n", nil, false},
+		{"carriage returns are ignored", args{"YQ==\r\nYg==\r\n", base64.StdEncoding, false, '\n', false}, "a\nb\n", nil, false},
+		{"URL encoding with no padding", args{"bG_Cow\nbG_Cow", base64.RawURLEncoding, false, '\n', false}, "lo£\nlo£\n", nil, false},
+		{"garbage is ignored per line", args{"Y*Q==\nY$g==\n", base64.StdEncoding, true, '\n', false}, "a\nb\n", nil, false},
+		{"NUL delimited records", args{"YQpi\x00Yw==\x00", base64.StdEncoding, false, 0, false}, "a\nb\x00c\x00", nil, false},
+		{"invalid line stops decoding", args{"YQ==\nY*==\nYg==\n", base64.StdEncoding, false, '\n', false}, "a\n", nil, true},
+		{"invalid lines are skipped", args{"YQ==\nY*==\nYg==\n!\n", base64.StdEncoding, false, '\n', true}, "a\nb\n", []int{2, 4}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				output     = &bytes.Buffer{}
+				gotInvalid []int
+				onInvalid  func(*LineError) error
+			)
+			if tt.args.skipInvalid {
+				onInvalid = func(lerr *LineError) error {
+					gotInvalid = append(gotInvalid, lerr.Line)
+					return nil
+				}
+			}
+			err := DecodeLines64(strings.NewReader(tt.args.input), output, tt.args.encoding, tt.args.ignoreGarbage, tt.args.delimiter, onInvalid)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeLines64() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("DecodeLines64() mismatch (-got +want):\n%s", diff)
+			}
+			if diff := cmp.Diff(gotInvalid, tt.wantInvalid); diff != "" {
+				t.Errorf("DecodeLines64() invalid lines mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package xbase
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// maxFirstLine is how far the first newline is searched for, longer input
+// without newline is considered not wrapped
+const maxFirstLine = 1024 * 1024
+
+// layoutChunk is size of blocks in which newlines of input are verified
+const layoutChunk = 64 * 1024
+
+// errRangeFull stops decoding when all requested data were read
+var errRangeFull = errors.New("range is full")
+
+// DetectWrap return length of the first line of input and of its newline
+// (2 for CRLF), line length is 0 when input is not wrapped
+func DetectWrap(input io.ReaderAt) (lineLength, newlineLength int64, err error) {
+	head := make([]byte, maxFirstLine)
+	n, err := input.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return 0, 0, err
+	}
+	i := bytes.IndexByte(head[:n], '\n')
+	switch {
+	case i < 0:
+		return 0, 0, nil
+	case i > 0 && head[i-1] == '\r':
+		return int64(i - 1), 2, nil
+	}
+	return int64(i), 1, nil
+}
+
+// ReaderAt read decoded data of base64 input at any offset. When lines of
+// input are wrapped regularly, like output of Encode64, position of data in
+// input is computed, so ReadAt decodes only the corresponding part of input,
+// otherwise input is decoded from its start.
+type ReaderAt struct {
+	input     io.ReaderAt
+	inputSize int64
+	encoding  *base64.Encoding
+
+	regular    bool
+	lineLength int64 // characters per line, 0 when input is not wrapped
+	newline    int64 // bytes of newline, 2 for CRLF
+	chars      int64 // base64 characters in input
+	size       int64 // decoded size
+}
+
+// NewReaderAt return ReaderAt of base64 input of size bytes padded or not
+// encoded by encoding, newlines of input are verified once, so the cost of
+// ReadAt of regularly wrapped input does not depend on its size
+func NewReaderAt(input io.ReaderAt, size int64, encoding *base64.Encoding) (*ReaderAt, error) {
+	if _, err := getAlphabet(encoding); err != nil {
+		return nil, err
+	}
+	r := &ReaderAt{input: input, inputSize: size, encoding: encoding}
+	if err := r.detectLayout(); err != nil {
+		return nil, err
+	}
+	if !r.regular {
+		counter := &rangeWriter{limit: -1, w: ioutil.Discard}
+		if err := r.decode(counter); err != nil {
+			return nil, err
+		}
+		r.size = counter.n
+	}
+	return r, nil
+}
+
+// Size return size of decoded data
+func (r *ReaderAt) Size() int64 {
+	return r.size
+}
+
+// detectLayout find wrapping of input by its first line, verify that all
+// other newlines are where the wrapping puts them and compute number of
+// characters and decoded size, input stays irregular otherwise
+func (r *ReaderAt) detectLayout() error {
+	lineLength, newline, err := DetectWrap(io.NewSectionReader(r.input, 0, r.inputSize))
+	if err != nil {
+		return fmt.Errorf("cannot read from input: %v", err)
+	}
+	if newline > 0 && lineLength == 0 {
+		return nil // empty first line
+	}
+	r.lineLength, r.newline = lineLength, newline
+
+	tail := make([]byte, min64(r.inputSize, 2))
+	if _, err := r.input.ReadAt(tail, r.inputSize-int64(len(tail))); err != nil && err != io.EOF {
+		return fmt.Errorf("cannot read from input: %v", err)
+	}
+	body := r.inputSize
+	switch {
+	case bytes.HasSuffix(tail, []byte("\r\n")):
+		body -= 2
+	case bytes.HasSuffix(tail, []byte("\n")):
+		body--
+	}
+	if ok, err := r.verifyNewlines(body); err != nil || !ok {
+		return err
+	}
+
+	// every line but the last has lineLength characters and newline
+	r.chars = body
+	if r.lineLength > 0 {
+		unit := r.lineLength + r.newline
+		r.chars = body/unit*r.lineLength + body%unit
+	}
+
+	switch r.chars % 4 {
+	case 0:
+		r.size = r.chars / 4 * 3
+		if r.chars > 0 {
+			last := make([]byte, 2)
+			if err := r.read(last, r.chars-2); err != nil {
+				return err
+			}
+			r.size -= int64(bytes.Count(last, []byte("=")))
+		}
+	case 1:
+		return nil // invalid, let decoding report it
+	default:
+		r.size = r.chars/4*3 + r.chars%4 - 1
+	}
+	r.regular = true
+	return nil
+}
+
+// verifyNewlines report whether newlines of the first body bytes of input
+// are exactly after every lineLength characters, the last line may be
+// shorter but not empty
+func (r *ReaderAt) verifyNewlines(body int64) (bool, error) {
+	unit := r.lineLength + r.newline
+	if r.lineLength > 0 && body%unit > r.lineLength {
+		return false, nil
+	}
+	if r.lineLength > 0 && body > 0 && body%unit == 0 {
+		return false, nil // empty last line
+	}
+	buffer := make([]byte, layoutChunk)
+	for offset := int64(0); offset < body; offset += layoutChunk {
+		chunk := buffer[:min64(layoutChunk, body-offset)]
+		if _, err := r.input.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return false, fmt.Errorf("cannot read from input: %v", err)
+		}
+		for i, c := range chunk {
+			var want byte // 0 for base64 character
+			if r.lineLength > 0 {
+				switch column := (offset + int64(i)) % unit; {
+				case column == unit-1:
+					want = '\n'
+				case column == r.lineLength && r.newline == 2:
+					want = '\r'
+				}
+			}
+			isNewline := c == '\n' || c == '\r'
+			if (want == 0 && isNewline) || (want != 0 && c != want) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// charOffset return offset in input of i-th base64 character of regularly
+// wrapped input
+func (r *ReaderAt) charOffset(i int64) int64 {
+	if r.lineLength == 0 {
+		return i
+	}
+	return i + i/r.lineLength*r.newline
+}
+
+// charLine return line of i-th base64 character of regularly wrapped input
+func (r *ReaderAt) charLine(i int64) int64 {
+	if r.lineLength == 0 {
+		return 1
+	}
+	return i/r.lineLength + 1
+}
+
+// read characters from i-th character of regularly wrapped input to chars
+func (r *ReaderAt) read(chars []byte, i int64) error {
+	if len(chars) == 0 {
+		return nil
+	}
+	start, end := r.charOffset(i), r.charOffset(i+int64(len(chars))-1)+1
+	buffer := make([]byte, end-start)
+	if _, err := r.input.ReadAt(buffer, start); err != nil && err != io.EOF {
+		return fmt.Errorf("cannot read from input: %v", err)
+	}
+	j := 0
+	for k := range chars {
+		if r.lineLength > 0 && k > 0 && (i+int64(k))%r.lineLength == 0 {
+			j += int(r.newline)
+		}
+		chars[k] = buffer[j]
+		j++
+	}
+	return nil
+}
+
+// ReadAt read len(p) decoded bytes at offset off to p
+func (r *ReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset")
+	}
+	if !r.regular {
+		buffer := &bytes.Buffer{}
+		err := r.decodeRange(buffer, off, int64(len(p)))
+		n := copy(p, buffer.Bytes())
+		switch {
+		case n == len(p):
+			return n, nil
+		case err != nil:
+			return n, err
+		}
+		return n, io.EOF
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	end := min64(off+int64(len(p)), r.size)
+
+	// decode whole quanta of 4 characters covering the range
+	first, last := off/3, (end+2)/3
+	chars := make([]byte, min64(last*4, r.chars)-first*4)
+	if err := r.read(chars, first*4); err != nil {
+		return 0, err
+	}
+	encoding := r.encoding
+	if len(chars)%4 != 0 {
+		encoding = encoding.WithPadding(base64.NoPadding)
+	}
+	decoded := make([]byte, encoding.DecodedLen(len(chars)))
+	dn, err := encoding.Decode(decoded, chars)
+	if err != nil {
+		if cerr, ok := err.(base64.CorruptInputError); ok {
+			i := first*4 + int64(cerr)
+			return 0, &OffsetError{Offset: r.charOffset(i), Line: r.charLine(i), Err: fmt.Errorf("illegal base64 data")}
+		}
+		return 0, err
+	}
+	n := copy(p, decoded[off-first*3:min64(end-first*3, int64(dn))])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// CopyRange write length decoded bytes at offset off to w, fewer when data
+// end sooner, irregularly wrapped input is decoded from its start only once
+func (r *ReaderAt) CopyRange(w io.Writer, off, length int64) error {
+	if off < 0 || length < 0 {
+		return fmt.Errorf("negative offset or length")
+	}
+	if !r.regular {
+		return r.decodeRange(w, off, length)
+	}
+	_, err := io.Copy(w, io.NewSectionReader(r, off, length))
+	return err
+}
+
+// decodeRange decode input from its start and write length bytes at offset
+// off to w
+func (r *ReaderAt) decodeRange(w io.Writer, off, length int64) error {
+	if length == 0 {
+		return nil
+	}
+	return r.decode(&rangeWriter{skip: off, limit: length, w: w})
+}
+
+// decode decode whole input to w, it stops early when w is full
+func (r *ReaderAt) decode(w *rangeWriter) error {
+	err := Decode64Tracked(io.NewSectionReader(r.input, 0, r.inputSize), w, r.encoding, false)
+	if w.full() {
+		return nil
+	}
+	return err
+}
+
+// rangeWriter skip the first bytes written to it and pass at most limit
+// following ones to w, negative limit means no limit
+type rangeWriter struct {
+	skip  int64
+	limit int64
+	n     int64 // bytes passed to w
+
+	w io.Writer
+}
+
+func (rw *rangeWriter) Write(data []byte) (int, error) {
+	size := len(data)
+	skipped := min64(rw.skip, int64(len(data)))
+	rw.skip -= skipped
+	data = data[skipped:]
+	if rw.limit >= 0 {
+		data = data[:min64(int64(len(data)), rw.limit-rw.n)]
+	}
+	n, err := rw.w.Write(data)
+	rw.n += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	if rw.full() {
+		return 0, errRangeFull
+	}
+	return size, nil
+}
+
+func (rw *rangeWriter) full() bool {
+	return rw.limit >= 0 && rw.n == rw.limit
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
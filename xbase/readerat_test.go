@@ -0,0 +1,195 @@
+package xbase
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// countingReaderAt count bytes read from ReaderAt
+type countingReaderAt struct {
+	r *strings.Reader
+	n int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.n += int64(n)
+	return n, err
+}
+
+func encoded64(t *testing.T, data []byte, encoding *base64.Encoding, wrapAfter uint) string {
+	t.Helper()
+	output := &bytes.Buffer{}
+	if err := Encode64(bytes.NewReader(data), output, encoding, wrapAfter); err != nil {
+		t.Fatalf("Encode64() error = %v", err)
+	}
+	return output.String()
+}
+
+func Test_DetectWrap(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantLine    int64
+		wantNewline int64
+	}{
+		{"aGVs\nbG8=\n", 4, 1},
+		{"aGVs\r\nbG8=\r\n", 4, 2},
+		{"aGVsbG8=", 0, 0},
+		{"", 0, 0},
+	}
+	for _, tt := range tests {
+		line, newline, err := DetectWrap(strings.NewReader(tt.input))
+		if err != nil || line != tt.wantLine || newline != tt.wantNewline {
+			t.Errorf("DetectWrap(%q) = %d, %d, %v, want %d, %d", tt.input, line, newline, err, tt.wantLine, tt.wantNewline)
+		}
+	}
+}
+
+func Test_ReaderAt(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	wrapped := encoded64(t, data, base64.StdEncoding, 76)
+	// size and length of the first line are consistent with regular wrapping
+	split := encoded64(t, data[:900], base64.StdEncoding, 76)
+	tests := []struct {
+		name        string
+		input       string
+		encoding    *base64.Encoding
+		wantRegular bool
+	}{
+		{"wrap after 76", wrapped, base64.StdEncoding, true},
+		{"wrap after 10", encoded64(t, data, base64.StdEncoding, 10), base64.StdEncoding, true},
+		{"wrap after 6", encoded64(t, data, base64.StdEncoding, 6), base64.StdEncoding, true},
+		{"no wrap", encoded64(t, data, base64.StdEncoding, 0), base64.StdEncoding, true},
+		{"no wrap with newline", encoded64(t, data, base64.StdEncoding, 0) + "\n", base64.StdEncoding, true},
+		{"CRLF", strings.Replace(wrapped, "\n", "\r\n", -1), base64.StdEncoding, true},
+		{"unpadded", strings.TrimRight(encoded64(t, data[:998], base64.StdEncoding, 76), "=\n"), base64.StdEncoding, true},
+		{"URL encoding", encoded64(t, data, base64.URLEncoding, 76), base64.URLEncoding, true},
+		{"irregular wrapping", wrapped[:100] + "\n" + wrapped[100:], base64.StdEncoding, false},
+		{"first line split", split[:40] + "\n" + split[40:], base64.StdEncoding, false},
+		{"wrapped without final newline", strings.TrimSuffix(wrapped, "\n"), base64.StdEncoding, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := &bytes.Buffer{}
+			if err := Decode64Tracked(strings.NewReader(tt.input), want, tt.encoding, false); err != nil {
+				t.Fatalf("Decode64Tracked() error = %v", err)
+			}
+			r, err := NewReaderAt(strings.NewReader(tt.input), int64(len(tt.input)), tt.encoding)
+			if err != nil {
+				t.Fatalf("NewReaderAt() error = %v", err)
+			}
+			if r.regular != tt.wantRegular {
+				t.Errorf("NewReaderAt() regular = %v, want %v", r.regular, tt.wantRegular)
+			}
+			if r.Size() != int64(want.Len()) {
+				t.Errorf("Size() = %d, want %d", r.Size(), want.Len())
+			}
+			for _, off := range []int64{0, 1, 2, 3, 56, 57, 100, 500, int64(want.Len()) - 7} {
+				for _, length := range []int64{0, 1, 2, 3, 4, 7, 60} {
+					got, err := ioutil.ReadAll(io.NewSectionReader(r, off, length))
+					if err != nil {
+						t.Fatalf("ReadAt(%d, %d) error = %v", off, length, err)
+					}
+					end := off + length
+					if end > int64(want.Len()) {
+						end = int64(want.Len())
+					}
+					if diff := cmp.Diff(got, want.Bytes()[off:end]); diff != "" {
+						t.Errorf("ReadAt(%d, %d) mismatch (-got +want):\n%s", off, length, diff)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_ReaderAt_readsOnlyRange(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	input := &countingReaderAt{r: strings.NewReader(encoded64(t, data, base64.StdEncoding, 76))}
+	r, err := NewReaderAt(input, input.r.Size(), base64.StdEncoding)
+	if err != nil {
+		t.Fatalf("NewReaderAt() error = %v", err)
+	}
+	input.n = 0
+	p := make([]byte, 100)
+	if _, err = r.ReadAt(p, 50000); err != nil {
+		t.Fatalf("ReadAt() error = %v", err)
+	}
+	if diff := cmp.Diff(p, data[50000:50100]); diff != "" {
+		t.Errorf("ReadAt() mismatch (-got +want):\n%s", diff)
+	}
+	if input.n > 200 {
+		t.Errorf("ReadAt() read %d bytes of input, want at most 200", input.n)
+	}
+}
+
+func Test_ReaderAt_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		off     int64
+		wantN   int
+		wantErr error
+	}{
+		{"read past end", "aGVsbG8=\n", 3, 2, io.EOF},
+		{"offset at end", "aGVsbG8=\n", 5, 0, io.EOF},
+		{"invalid character", "aGVs\nbG8g\nd2!y\nbGQ=\n", 6, 0, &OffsetError{Offset: 12, Line: 3}},
+		{"invalid character of irregular input", "aGVs\n\nbG8g\nd2!y\nbGQ=\n", 6, 0, &OffsetError{Offset: 13, Line: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewReaderAt(strings.NewReader(tt.input), int64(len(tt.input)), base64.StdEncoding)
+			if err == nil {
+				p := make([]byte, 4)
+				var n int
+				n, err = r.ReadAt(p, tt.off)
+				if n != tt.wantN {
+					t.Errorf("ReadAt() = %d, want %d", n, tt.wantN)
+				}
+			}
+			if offsetErr, ok := tt.wantErr.(*OffsetError); ok {
+				got, ok := err.(*OffsetError)
+				if !ok || got.Offset != offsetErr.Offset || got.Line != offsetErr.Line {
+					t.Errorf("error = %v, want %v", err, offsetErr)
+				}
+				return
+			}
+			if err != tt.wantErr {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ReaderAt_CopyRangeOfIrregularInput(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	wrapped := encoded64(t, data, base64.StdEncoding, 76)
+	input := &countingReaderAt{r: strings.NewReader(wrapped[:40] + "\n" + wrapped[40:])}
+	r, err := NewReaderAt(input, input.r.Size(), base64.StdEncoding)
+	if err != nil {
+		t.Fatalf("NewReaderAt() error = %v", err)
+	}
+	if r.regular {
+		t.Fatalf("NewReaderAt() regular = true, want false")
+	}
+	input.n = 0
+	output := &bytes.Buffer{}
+	if err = r.CopyRange(output, 1, int64(len(data))); err != nil {
+		t.Fatalf("CopyRange() error = %v", err)
+	}
+	if !bytes.Equal(output.Bytes(), data[1:]) {
+		t.Errorf("CopyRange() output differs, %d bytes, want %d", output.Len(), len(data)-1)
+	}
+	if input.n > input.r.Size() {
+		t.Errorf("CopyRange() read %d bytes of input, want at most %d", input.n, input.r.Size())
+	}
+}
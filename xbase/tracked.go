@@ -0,0 +1,101 @@
+package xbase
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// OffsetError is a decoding error at Offset of the original input, which
+// includes newlines and garbage dropped before decoding
+type OffsetError struct {
+	Offset int64 // byte offset from the start of input
+	Line   int64 // line number starting at 1
+	Err    error
+}
+
+func (e *OffsetError) Error() string {
+	return fmt.Sprintf("invalid input at byte %d (line %d): %v", e.Offset, e.Line, e.Err)
+}
+
+// Decode64Tracked read stream from input and decode it to output as Decode64
+// does, but decoding errors are *OffsetError with offsets of the original
+// input, input may be padded or not
+func Decode64Tracked(input io.Reader, output io.Writer, encoding *base64.Encoding, ignoreGarbage bool) error {
+	alphabet, err := getAlphabet(encoding)
+	if err != nil {
+		return err
+	}
+	const flushAfter = 32 * 1024 // multiple of 4, so we only decode whole quanta
+
+	var (
+		buffer  = make([]byte, 32*1024)
+		pending = make([]byte, 0, flushAfter)
+		offsets = make([]int64, 0, flushAfter) // original offset of pending characters
+		lines   = make([]int64, 0, flushAfter) // line of pending characters
+		decoded = make([]byte, encoding.DecodedLen(flushAfter))
+		offset  int64 // original offset of the next input byte
+		line    = int64(1)
+		padded  bool // padding was decoded, no more data may follow
+	)
+
+	fail := func(i int, err error) error {
+		if i < len(offsets) {
+			return &OffsetError{Offset: offsets[i], Line: lines[i], Err: err}
+		}
+		return &OffsetError{Offset: offset, Line: line, Err: err}
+	}
+	flush := func(final bool) error {
+		if len(pending) == 0 {
+			return nil
+		}
+		enc := encoding
+		if final && len(pending)%4 != 0 && pending[len(pending)-1] != '=' {
+			enc = encoding.WithPadding(base64.NoPadding)
+		}
+		n, err := enc.Decode(decoded, pending)
+		if _, werr := output.Write(decoded[:n]); werr != nil {
+			return fmt.Errorf("cannot write to output: %v", werr)
+		}
+		if err != nil {
+			if cerr, ok := err.(base64.CorruptInputError); ok {
+				return fail(int(cerr), fmt.Errorf("illegal base64 data"))
+			}
+			return fail(0, err)
+		}
+		padded = pending[len(pending)-1] == '='
+		pending, offsets, lines = pending[:0], offsets[:0], lines[:0]
+		return nil
+	}
+
+	for {
+		n, err := input.Read(buffer)
+		for _, char := range buffer[:n] {
+			switch {
+			case char == '\n':
+				line++
+			case char == '\r', ignoreGarbage && !alphabet[char]:
+			default:
+				if padded {
+					return &OffsetError{Offset: offset, Line: line, Err: fmt.Errorf("data after padding")}
+				}
+				pending = append(pending, char)
+				offsets = append(offsets, offset)
+				lines = append(lines, line)
+				if len(pending) == flushAfter {
+					if ferr := flush(false); ferr != nil {
+						return ferr
+					}
+				}
+			}
+			offset++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read from input: %v", err)
+		}
+	}
+	return flush(true)
+}
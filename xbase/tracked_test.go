@@ -0,0 +1,77 @@
+package xbase
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Decode64Tracked(t *testing.T) {
+	type args struct {
+		input         string
+		encoding      *base64.Encoding
+		ignoreGarbage bool
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantOutput string
+		wantErr    *OffsetError
+	}{
+		{"empty input", args{"", base64.StdEncoding, false}, "", nil},
+		{"wrapped", args{"aGVs\nbG8g\r\nd29y\nbGQ=\n", base64.StdEncoding, false}, "hello world", nil},
+		{"unpadded", args{"aGVsbG8", base64.StdEncoding, false}, "hello", nil},
+		{"URL encoding", args{"-_8=", base64.URLEncoding, false}, "\xfb\xff", nil},
+		{"garbage is ignored", args{"aG!Vs\nbG8=", base64.StdEncoding, true}, "hello", nil},
+		{"offset includes newlines", args{"aGVs\nbG8g\nd2!y\n", base64.StdEncoding, false}, "hello ", &OffsetError{Offset: 12, Line: 3}},
+		{"offset includes dropped garbage", args{"a!GVsb*G8g\n~d2$9y\r\nQ=Q", base64.StdEncoding, true}, "hello wor", &OffsetError{Offset: 20, Line: 3}},
+		{"data after padding", args{"YQ==\nYg==\n", base64.StdEncoding, false}, "a", &OffsetError{Offset: 5, Line: 2}},
+		{"standard character in URL encoding", args{"aGVs\n+G8=", base64.URLEncoding, false}, "hel", &OffsetError{Offset: 5, Line: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			err := Decode64Tracked(strings.NewReader(tt.args.input), output, tt.args.encoding, tt.args.ignoreGarbage)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Decode64Tracked() error = %v, want nil", err)
+				}
+			} else {
+				oerr, ok := err.(*OffsetError)
+				if !ok {
+					t.Fatalf("Decode64Tracked() error = %v, want *OffsetError", err)
+				}
+				if oerr.Offset != tt.wantErr.Offset || oerr.Line != tt.wantErr.Line {
+					t.Errorf("Decode64Tracked() error at byte %d line %d, want byte %d line %d", oerr.Offset, oerr.Line, tt.wantErr.Offset, tt.wantErr.Line)
+				}
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("Decode64Tracked() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Decode64TrackedLargeInput(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	encoded := &bytes.Buffer{}
+	if err := Encode64(bytes.NewReader(data), encoded, base64.StdEncoding, 76); err != nil {
+		t.Fatalf("Encode64() error = %v", err)
+	}
+	// corrupt the last line of input, far beyond the first decoded chunk
+	corrupted := encoded.Bytes()
+	at := bytes.LastIndexByte(corrupted[:len(corrupted)-1], '\n') + 3
+	corrupted[at] = '*'
+
+	err := Decode64Tracked(bytes.NewReader(corrupted), &bytes.Buffer{}, base64.StdEncoding, false)
+	oerr, ok := err.(*OffsetError)
+	if !ok {
+		t.Fatalf("Decode64Tracked() error = %v, want *OffsetError", err)
+	}
+	if wantLine := int64(bytes.Count(corrupted, []byte("\n"))); oerr.Offset != int64(at) || oerr.Line != wantLine {
+		t.Errorf("Decode64Tracked() error at byte %d line %d, want byte %d line %d", oerr.Offset, oerr.Line, at, wantLine)
+	}
+}
@@ -8,10 +8,18 @@ import (
 
 // Encode64 read stream from input and encode it to base64 with optional wrapping
 func Encode64(input io.Reader, output io.Writer, encoding *base64.Encoding, wrapAfter uint) error {
+	return encode(input, output, func(w io.Writer) io.WriteCloser {
+		return base64.NewEncoder(encoding, w)
+	}, wrapAfter)
+}
+
+// encode read stream from input and encode it with encoder created by
+// newEncoder with optional wrapping
+func encode(input io.Reader, output io.Writer, newEncoder func(io.Writer) io.WriteCloser, wrapAfter uint) error {
 
 	wrapper := &wrapWriter{wrapAfter: int(wrapAfter), w: output}
 
-	if err := plainEncode(input, wrapper, encoding); err != nil {
+	if err := plainEncodeWith(input, newEncoder(wrapper)); err != nil {
 		return fmt.Errorf("cannot encode: %v", err)
 	}
 
@@ -24,13 +32,15 @@ func Encode64(input io.Reader, output io.Writer, encoding *base64.Encoding, wrap
 }
 
 func plainEncode(input io.Reader, output io.Writer, encoding *base64.Encoding) (err error) {
+	return plainEncodeWith(input, base64.NewEncoder(encoding, output))
+}
+
+func plainEncodeWith(input io.Reader, encoder io.WriteCloser) (err error) {
 	buffer := make([]byte, 32*1024)
-	encoder := base64.NewEncoder(encoding, output)
 	defer func() {
 		if derr := encoder.Close(); derr != nil {
 			err = fmt.Errorf("cannot close encoder: %v, %v", derr, err)
 		}
-		fmt.Fprint(output)
 	}()
 
 	for {
@@ -99,28 +109,57 @@ func (ww *wrapWriter) AddMissingNewline() (err error) {
 
 // Decode64 read stream from input and decode it output with optional garbade ignoring
 func Decode64(input io.Reader, output io.Writer, encoding *base64.Encoding, ignoreGarbage bool) error {
-	var (
-		alphabet alphabet
-	)
+	alphabet, err := getAlphabet(encoding)
+	if err != nil {
+		return err
+	}
 
-	switch encoding {
-	case base64.StdEncoding, base64.RawStdEncoding:
-		alphabet = base64std
-	case base64.URLEncoding, base64.RawURLEncoding:
-		alphabet = base64url
-	default:
-		return fmt.Errorf("encoding is not supported")
+	return decode(input, output, alphabet, ignoreGarbage, func(r io.Reader) io.Reader {
+		return base64.NewDecoder(encoding, r)
+	})
+}
+
+// decode read stream from input and decode it with decoder created by
+// newDecoder with optional garbage ignoring
+func decode(input io.Reader, output io.Writer, alphabet alphabet, ignoreGarbage bool, newDecoder func(io.Reader) io.Reader) error {
+	sweeper := &garboReader{alphabet: alphabet, ignoreGarbage: ignoreGarbage, r: input}
+
+	if err := plainDecodeFrom(newDecoder(sweeper), output); err != nil {
+		return fmt.Errorf("cannot decode: %v", err)
+	}
+
+	return nil
+}
+
+// Decode64Segments read stream of independently padded base64 segments
+// concatenated back to back (e.g. "YQ==Yg==") from input and decode them
+// to output the same way as GNU base64 does
+func Decode64Segments(input io.Reader, output io.Writer, encoding *base64.Encoding, ignoreGarbage bool) error {
+	alphabet, err := getAlphabet(encoding)
+	if err != nil {
+		return err
 	}
 
 	sweeper := &garboReader{alphabet: alphabet, ignoreGarbage: ignoreGarbage, r: input}
 
-	if err := plainDecode(sweeper, output, encoding); err != nil {
+	if err := plainDecodeSegments(sweeper, output, encoding); err != nil {
 		return fmt.Errorf("cannot decode: %v", err)
 	}
 
 	return nil
 }
 
+func getAlphabet(encoding *base64.Encoding) (alphabet, error) {
+	switch encoding {
+	case base64.StdEncoding, base64.RawStdEncoding:
+		return base64std, nil
+	case base64.URLEncoding, base64.RawURLEncoding:
+		return base64url, nil
+	default:
+		return alphabet{}, fmt.Errorf("encoding is not supported")
+	}
+}
+
 type garboReader struct {
 	alphabet      alphabet
 	ignoreGarbage bool
@@ -150,8 +189,11 @@ func (gr *garboReader) Read(p []byte) (n int, err error) {
 }
 
 func plainDecode(input io.Reader, output io.Writer, encoding *base64.Encoding) (err error) {
+	return plainDecodeFrom(base64.NewDecoder(encoding, input), output)
+}
+
+func plainDecodeFrom(decoder io.Reader, output io.Writer) (err error) {
 	buffer := make([]byte, 32*1024)
-	decoder := base64.NewDecoder(encoding, input)
 	for {
 		n, err := decoder.Read(buffer)
 		if err != nil {
@@ -167,3 +209,58 @@ func plainDecode(input io.Reader, output io.Writer, encoding *base64.Encoding) (
 
 	return nil
 }
+
+// plainDecodeSegments decode input in 4 byte quanta and start a new segment
+// after every quantum ending with padding, so padding does not end the stream
+func plainDecodeSegments(input io.Reader, output io.Writer, encoding *base64.Encoding) error {
+	const flushAfter = 32 * 1024 // multiple of 4, so we only flush whole quanta
+
+	var (
+		buffer  = make([]byte, 32*1024)
+		pending = make([]byte, 0, flushAfter)
+		decoded = make([]byte, encoding.DecodedLen(flushAfter))
+		offset  int64 // position of pending[0] in the input without newlines
+	)
+
+	flush := func() error {
+		n, err := encoding.Decode(decoded, pending)
+		if _, werr := output.Write(decoded[:n]); werr != nil {
+			return fmt.Errorf("cannot write to output: %v", werr)
+		}
+		if err != nil {
+			if cerr, ok := err.(base64.CorruptInputError); ok {
+				err = base64.CorruptInputError(offset + int64(cerr))
+			}
+			return fmt.Errorf("decoder cannot read from buffer: %v", err)
+		}
+		offset += int64(len(pending))
+		pending = pending[:0]
+		return nil
+	}
+
+	for {
+		n, err := input.Read(buffer)
+		for _, char := range buffer[:n] {
+			if char == '\r' || char == '\n' {
+				continue
+			}
+			pending = append(pending, char)
+			if len(pending)%4 == 0 && (char == '=' || len(pending) == flushAfter) {
+				if ferr := flush(); ferr != nil {
+					return ferr
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read from input: %v", err)
+		}
+	}
+
+	if len(pending) > 0 {
+		return flush()
+	}
+	return nil
+}
@@ -310,6 +310,80 @@ func Test_Decode64(t *testing.T) {
 	}
 }
 
+func Test_Decode64Segments(t *testing.T) {
+	type args struct {
+		fileName      string
+		encoding      *base64.Encoding
+		ignoreGarbage bool
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantFileName string
+		wantErr      bool
+	}{
+		{"Standard encoding with concatenated segments and no garbage", args{"testdata/segments.decode.std.wrap-0.no-garbage.concatenated.input", base64.StdEncoding, false}, "testdata/segments.decode.std.wrap-0.no-garbage.concatenated.gold", false},
+		{"Standard encoding with concatenated segments and with garbage", args{"testdata/segments.decode.std.wrap-0.std-garbage.concatenated.input", base64.StdEncoding, true}, "testdata/segments.decode.std.wrap-0.std-garbage.concatenated.gold", false},
+		{"Standard encoding with padding and no garbage and wrap after 76", args{"testdata/100c.decode.std.wrap-76.no-garbage.padded.input", base64.StdEncoding, false}, "testdata/100c.decode.std.wrap-76.no-garbage.padded.gold", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Prepare file input
+			file, err := os.Open(tt.args.fileName)
+			if err != nil {
+				t.Fatalf("cannot open %s: %v", tt.args.fileName, err)
+			}
+			defer file.Close()
+
+			output := &bytes.Buffer{}
+
+			// Execute
+			err = Decode64Segments(file, output, tt.args.encoding, tt.args.ignoreGarbage)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode64Segments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			wantOutput, err := ioutil.ReadFile(tt.wantFileName)
+			if err != nil {
+				t.Fatalf("Cannot read file %s: %v", tt.wantFileName, err)
+			}
+
+			if diff := cmp.Diff(output.String(), string(wantOutput)); diff != "" {
+				t.Errorf("Decode64Segments() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_plainDecodeSegments(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantOutput string
+		wantErr    string
+	}{
+		{"empty input", "", "", ""},
+		{"single segment", "YQ==", "a", ""},
+		{"two padded segments", "YQ==Yg==", "ab", ""},
+		{"padded segments separated by newlines", "YQ==\r\nYWI=\nYWJj", "aababc", ""},
+		{"unpadded tail is decoded", "YWI=YWJj", "ababc", ""},
+		{"corrupt second segment keeps first one", "YWI=Y*Jj", "ab", "illegal base64 data at input byte 5"},
+		{"truncated last segment", "YWI=YQ", "ab", "illegal base64 data at input byte 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			err := plainDecodeSegments(strings.NewReader(tt.input), output, base64.StdEncoding)
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("plainDecodeSegments() error = %v, wantErr %q", err, tt.wantErr)
+			}
+			if gotOutput := output.String(); gotOutput != tt.wantOutput {
+				t.Errorf("plainDecodeSegments() = %q, want %q", gotOutput, tt.wantOutput)
+			}
+		})
+	}
+}
+
 func Benchmark_Decode64_noIgnoreGarbage(b *testing.B) {
 	ignoreGarbage := false
 	testInput := "testdata/utf8.decode.url.wrap-0.padded.input"
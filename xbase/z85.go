@@ -0,0 +1,142 @@
+package xbase
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// z85Chars is the alphabet of ZeroMQ Z85 encoding (https://rfc.zeromq.org/spec/32/)
+const z85Chars = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+
+var z85Values = func() (v [256]byte) {
+	for i := range v {
+		v[i] = 0xff
+	}
+	for i := 0; i < len(z85Chars); i++ {
+		v[z85Chars[i]] = byte(i)
+	}
+	return v
+}()
+
+// EncodeZ85 read stream from input and encode it to Z85 with optional
+// wrapping, length of input must be a multiple of 4
+func EncodeZ85(input io.Reader, output io.Writer, wrapAfter uint) error {
+	return encode(input, output, func(w io.Writer) io.WriteCloser {
+		return &z85Encoder{w: w}
+	}, wrapAfter)
+}
+
+// DecodeZ85 read Z85 stream from input and decode it to output with optional
+// garbage ignoring, length of input must be a multiple of 5
+func DecodeZ85(input io.Reader, output io.Writer, ignoreGarbage bool) error {
+	alphabet := noNewlines
+	if ignoreGarbage {
+		alphabet = z85
+	}
+	return decode(input, output, alphabet, true, func(r io.Reader) io.Reader {
+		return &z85Decoder{r: r}
+	})
+}
+
+type z85Encoder struct {
+	leftover  [4]byte
+	nleftover int
+
+	w io.Writer
+}
+
+func (e *z85Encoder) Write(p []byte) (n int, err error) {
+	n = len(p)
+	b := make([]byte, 0, (len(p)+e.nleftover)/4*5)
+	for len(p) > 0 {
+		c := copy(e.leftover[e.nleftover:], p)
+		e.nleftover += c
+		p = p[c:]
+		if e.nleftover < 4 {
+			break
+		}
+		b = appendZ85(b, e.leftover[:])
+		e.nleftover = 0
+	}
+	if _, err = e.w.Write(b); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (e *z85Encoder) Close() error {
+	if e.nleftover != 0 {
+		return fmt.Errorf("invalid input (length must be multiple of 4 characters)")
+	}
+	return nil
+}
+
+// appendZ85 append 5 characters encoding 4 bytes of group to b
+func appendZ85(b []byte, group []byte) []byte {
+	value := binary.BigEndian.Uint32(group)
+	var chars [5]byte
+	for i := 4; i >= 0; i-- {
+		chars[i] = z85Chars[value%85]
+		value /= 85
+	}
+	return append(b, chars[:]...)
+}
+
+type z85Decoder struct {
+	err    error
+	offset int64 // number of characters already decoded
+	in     [5 * 1024]byte
+	nin    int
+	out    [4 * 1024]byte
+	pend   []byte // decoded bytes not returned yet
+
+	r io.Reader
+}
+
+func (d *z85Decoder) Read(p []byte) (n int, err error) {
+	if len(d.pend) > 0 {
+		n = copy(p, d.pend)
+		d.pend = d.pend[n:]
+		return n, nil
+	}
+	if d.err != nil && d.err != io.EOF {
+		return 0, d.err
+	}
+
+	for d.nin < 5 && d.err == nil {
+		var nr int
+		nr, d.err = d.r.Read(d.in[d.nin:])
+		d.nin += nr
+	}
+	if d.nin < 5 {
+		if d.err == io.EOF && d.nin > 0 {
+			d.err = fmt.Errorf("invalid input (length must be multiple of 5 characters)")
+		}
+		return 0, d.err
+	}
+
+	groups := d.nin / 5
+	for g := 0; g < groups; g++ {
+		var value uint64
+		for i, char := range d.in[g*5 : g*5+5] {
+			v := z85Values[char]
+			if v == 0xff {
+				d.err = fmt.Errorf("illegal z85 data at input byte %d", d.offset+int64(g*5+i))
+				d.pend = d.out[:g*4]
+				return d.Read(p)
+			}
+			value = value*85 + uint64(v)
+		}
+		if value > 0xffffffff {
+			d.err = fmt.Errorf("illegal z85 data at input byte %d", d.offset+int64(g*5))
+			d.pend = d.out[:g*4]
+			return d.Read(p)
+		}
+		binary.BigEndian.PutUint32(d.out[g*4:], uint32(value))
+	}
+	d.offset += int64(groups * 5)
+	d.nin = copy(d.in[:], d.in[groups*5:d.nin])
+	d.pend = d.out[:groups*4]
+	return d.Read(p)
+}
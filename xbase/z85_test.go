@@ -0,0 +1,67 @@
+package xbase
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_EncodeZ85(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wrapAfter  uint
+		wantOutput string
+		wantErr    bool
+	}{
+		{"empty input", "", 76, "", false},
+		{"ZeroMQ spec test vector", "\x86\x4F\xD2\x6F\xB5\x59\xF7\x5B", 76, "HelloWorld\n", false},
+		{"wrap after 4", "hello world!", 4, "xK#0\n@zY<\nmxA+\n]nf\n", false},
+		{"input length not multiple of 4", "hello", 0, "xK#0@", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			if err := EncodeZ85(strings.NewReader(tt.input), output, tt.wrapAfter); (err != nil) != tt.wantErr {
+				t.Errorf("EncodeZ85() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("EncodeZ85() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_DecodeZ85(t *testing.T) {
+	type args struct {
+		input         string
+		ignoreGarbage bool
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantOutput string
+		wantErr    bool
+	}{
+		{"empty input", args{"", false}, "", false},
+		{"ZeroMQ spec test vector", args{"HelloWorld", false}, "\x86\x4F\xD2\x6F\xB5\x59\xF7\x5B", false},
+		{"newlines are ignored", args{"xK#0\n@zY<\r\nmxA+\n]nf\n", false}, "hello world!", false},
+		{"garbage fails after valid group", args{"xK#0@zY<m~xA+]nf", false}, "hell", true},
+		{"garbage is ignored", args{"xK#0@ zY<mx~A+]nf", true}, "hello world!", false},
+		{"input length not multiple of 5", args{"xK#0@zY", false}, "hell", true},
+		{"group value overflow", args{"#####", false}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			if err := DecodeZ85(strings.NewReader(tt.args.input), output, tt.args.ignoreGarbage); (err != nil) != tt.wantErr {
+				t.Errorf("DecodeZ85() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(output.String(), tt.wantOutput); diff != "" {
+				t.Errorf("DecodeZ85() mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}